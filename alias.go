@@ -0,0 +1,96 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	aliasTagName      = `alias`
+	deprecatedTagName = `deprecated`
+)
+
+// lookupField finds the input key and value for a struct field in
+// dataVal. Besides its key, a field accepts the comma separated names in
+// its "alias" tag and, with a warning, those in its "deprecated" tag.
+// Giving more than one of them is an error.
+func (d *Decoder) lookupField(name, fieldName string, field reflect.StructField, dataVal reflect.Value, dataValKeys map[reflect.Value]struct{}) (reflect.Value, reflect.Value, error) {
+	path := fieldName
+	if name != "" {
+		path = fmt.Sprintf("%s.%s", name, fieldName)
+	}
+
+	aliases := splitTagList(field.Tag.Get(aliasTagName))
+	deprecated := splitTagList(field.Tag.Get(deprecatedTagName))
+	names := append(append([]string{fieldName}, aliases...), deprecated...)
+
+	var foundKey, rawMapVal reflect.Value
+	var foundName string
+	for _, n := range names {
+		key, val := lookupMapKey(dataVal, dataValKeys, n)
+		if !val.IsValid() || (foundKey.IsValid() && key.Interface() == foundKey.Interface()) {
+			continue
+		}
+
+		if foundKey.IsValid() {
+			return reflect.Value{}, reflect.Value{}, fmt.Errorf(
+				"'%s' is given more than once, as '%v' and '%v'", path, foundKey, key)
+		}
+
+		foundKey, rawMapVal, foundName = key, val, n
+	}
+
+	if !foundKey.IsValid() {
+		return foundKey, rawMapVal, nil
+	}
+
+	if foundKey.Interface() != foundName {
+		d.warn(path, WarningCaseInsensitiveKey,
+			"key '%v' matched '%s' case-insensitively", foundKey, foundName)
+	}
+
+	for _, n := range deprecated {
+		if n == foundName {
+			d.warn(path, WarningDeprecatedKey,
+				"key '%s' is deprecated, use '%s' instead", foundName, fieldName)
+		}
+	}
+
+	return foundKey, rawMapVal, nil
+}
+
+// lookupMapKey returns the key and value for key in dataVal. If there is
+// no exact match it does a slower case-insensitive search over all keys.
+func lookupMapKey(dataVal reflect.Value, dataValKeys map[reflect.Value]struct{}, key string) (reflect.Value, reflect.Value) {
+	rawMapKey := reflect.ValueOf(key)
+	rawMapVal := dataVal.MapIndex(rawMapKey)
+	if rawMapVal.IsValid() {
+		return rawMapKey, rawMapVal
+	}
+
+	for dataValKey := range dataValKeys {
+		mK, ok := dataValKey.Interface().(string)
+		if !ok {
+			// Not a string key
+			continue
+		}
+
+		if strings.EqualFold(mK, key) {
+			return dataValKey, dataVal.MapIndex(dataValKey)
+		}
+	}
+
+	return reflect.Value{}, reflect.Value{}
+}
+
+func splitTagList(tagValue string) []string {
+	var names []string
+	for _, name := range strings.Split(tagValue, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,79 @@
+package mapstructure
+
+import (
+	"reflect"
+	"testing"
+)
+
+type AliasConfig struct {
+	Timeout int `json:"timeout" alias:"timeout_ms" deprecated:"timeoutMs, time_out"`
+	Name    string
+}
+
+func TestDecode_Alias(t *testing.T) {
+	t.Parallel()
+
+	var result AliasConfig
+	d := &Decoder{Result: &result}
+	if err := d.Decode(map[string]interface{}{"TIMEOUT_MS": 10}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.Timeout != 10 {
+		t.Errorf("bad: %#v", result)
+	}
+
+	expected := []Warning{{
+		Path:    "timeout",
+		Code:    WarningCaseInsensitiveKey,
+		Message: "key 'TIMEOUT_MS' matched 'timeout_ms' case-insensitively",
+	}}
+	if !reflect.DeepEqual(d.Warnings, expected) {
+		t.Errorf("bad warnings: %#v", d.Warnings)
+	}
+}
+
+func TestDecode_AliasDeprecated(t *testing.T) {
+	t.Parallel()
+
+	var result struct {
+		Nested AliasConfig `json:"nested"`
+	}
+	d := &Decoder{Result: &result}
+	input := map[string]interface{}{
+		"nested": map[string]interface{}{
+			"timeoutMs": 10,
+		},
+	}
+	if err := d.Decode(input); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.Nested.Timeout != 10 {
+		t.Errorf("bad: %#v", result)
+	}
+
+	expected := []Warning{{
+		Path:    "nested.timeout",
+		Code:    WarningDeprecatedKey,
+		Message: "key 'timeoutMs' is deprecated, use 'timeout' instead",
+	}}
+	if !reflect.DeepEqual(d.Warnings, expected) {
+		t.Errorf("bad warnings: %#v", d.Warnings)
+	}
+}
+
+func TestDecode_AliasConflict(t *testing.T) {
+	t.Parallel()
+
+	var result AliasConfig
+	err := Decode(map[string]interface{}{"timeout": 1, "time_out": 2}, &result)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	expected := "1 error(s) decoding:\n\n* 'timeout' is given more than once, as 'timeout' and 'time_out'"
+	if err.Error() != expected {
+		t.Fatalf("bad error: %s", err)
+	}
+}
@@ -0,0 +1,152 @@
+package mapstructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ComplexFormat is how complex numbers are represented when a struct is
+// encoded into a map.
+type ComplexFormat uint8
+
+const (
+	// ComplexNative keeps complex numbers as they are. It is the default.
+	ComplexNative ComplexFormat = iota
+
+	// ComplexString formats complex numbers as strings such as "1+2i".
+	ComplexString
+
+	// ComplexPair encodes complex numbers as a []interface{} holding the
+	// real and imaginary parts as float64.
+	ComplexPair
+
+	// ComplexMap encodes complex numbers as a map[string]interface{} with
+	// "real" and "imag" keys holding float64.
+	ComplexMap
+)
+
+// complexParts is the map form of a complex number.
+type complexParts struct {
+	Real float64 `json:"real"`
+	Imag float64 `json:"imag"`
+}
+
+func (d *Decoder) decodeComplex(name string, data interface{}, val reflect.Value) error {
+	dataVal := reflect.Indirect(reflect.ValueOf(data))
+	dataKind := getKind(dataVal)
+	dataType := dataVal.Type()
+
+	switch {
+	case dataKind == reflect.Complex64:
+		val.SetComplex(dataVal.Complex())
+	case dataKind == reflect.Int:
+		val.SetComplex(complex(float64(dataVal.Int()), 0))
+	case dataKind == reflect.Uint:
+		val.SetComplex(complex(float64(dataVal.Uint()), 0))
+	case dataKind == reflect.Float32:
+		val.SetComplex(complex(dataVal.Float(), 0))
+	case dataType.PkgPath() == "encoding/json" && dataType.Name() == "Number":
+		jn := data.(json.Number)
+		f, err := jn.Float64()
+		if err != nil {
+			return fmt.Errorf("error decoding json.Number into %s: %s", name, err)
+		}
+		val.SetComplex(complex(f, 0))
+	case dataKind == reflect.Slice || dataKind == reflect.Array:
+		if dataVal.Len() != 2 {
+			return fmt.Errorf(
+				"'%s' expected the real and imaginary parts, got %d element(s)",
+				name, dataVal.Len())
+		}
+
+		var parts [2]float64
+		if err := d.decode(name, dataVal.Interface(), reflect.ValueOf(&parts).Elem()); err != nil {
+			return err
+		}
+		val.SetComplex(complex(parts[0], parts[1]))
+	case dataKind == reflect.Map:
+		var parts complexParts
+		if err := d.decode(name, dataVal.Interface(), reflect.ValueOf(&parts).Elem()); err != nil {
+			return err
+		}
+		val.SetComplex(complex(parts.Real, parts.Imag))
+	default:
+		return fmt.Errorf(
+			"'%s' expected type '%s', got unconvertible type '%s'",
+			name, val.Type(), dataVal.Type())
+	}
+
+	return nil
+}
+
+// encodeComplex returns c in the configured ComplexFormat.
+func (d *Decoder) encodeComplex(c complex128) interface{} {
+	switch d.ComplexFormat {
+	case ComplexString:
+		return formatComplex(c)
+	case ComplexPair:
+		return []interface{}{real(c), imag(c)}
+	case ComplexMap:
+		return map[string]interface{}{"real": real(c), "imag": imag(c)}
+	default:
+		return c
+	}
+}
+
+// parseComplex parses strings such as "1+2i", "-3.5i", "4" or "(1-2i)".
+func parseComplex(s string) (complex128, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = s[1 : len(s)-1]
+	}
+
+	if !strings.HasSuffix(s, "i") {
+		re, err := strconv.ParseFloat(s, 64)
+		return complex(re, 0), err
+	}
+
+	// Split before the sign of the imaginary part, skipping the sign of
+	// the real part and those of exponents.
+	body := s[:len(s)-1]
+	split := 0
+	for i := len(body) - 1; i > 0; i-- {
+		if (body[i] == '+' || body[i] == '-') && body[i-1] != 'e' && body[i-1] != 'E' {
+			split = i
+			break
+		}
+	}
+
+	reStr, imStr := "0", body[split:]
+	if split > 0 {
+		reStr = body[:split]
+	}
+	switch imStr {
+	case "", "+":
+		imStr = "1"
+	case "-":
+		imStr = "-1"
+	}
+
+	re, err := strconv.ParseFloat(reStr, 64)
+	if err != nil {
+		return 0, err
+	}
+	im, err := strconv.ParseFloat(imStr, 64)
+	if err != nil {
+		return 0, err
+	}
+	return complex(re, im), nil
+}
+
+// formatComplex formats c like "1+2i", the inverse of parseComplex.
+func formatComplex(c complex128) string {
+	im := strconv.FormatFloat(imag(c), 'g', -1, 64)
+	if im[0] != '-' && im[0] != '+' {
+		im = "+" + im
+	}
+
+	return strconv.FormatFloat(real(c), 'g', -1, 64) + im + "i"
+}
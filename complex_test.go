@@ -0,0 +1,166 @@
+package mapstructure
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type Complex struct {
+	Z   complex128 `json:"z"`
+	Z64 complex64  `json:"z64"`
+}
+
+func TestDecode_complex(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input    interface{}
+		expected complex128
+	}{
+		{complex(1, 2), 1 + 2i},
+		{complex64(complex(3, -4)), 3 - 4i},
+		{5, 5},
+		{uint8(6), 6},
+		{7.5, 7.5},
+		{json.Number("8.25"), 8.25},
+		{[]interface{}{1, 2.5}, 1 + 2.5i},
+		{[2]float64{-1, -2}, -1 - 2i},
+		{map[string]interface{}{"real": 3, "imag": 4}, 3 + 4i},
+		{map[string]interface{}{"imag": 1}, 1i},
+	}
+
+	for _, tc := range cases {
+		var result Complex
+		err := Decode(map[string]interface{}{"z": tc.input, "z64": tc.input}, &result)
+		if err != nil {
+			t.Fatalf("%#v: err: %s", tc.input, err)
+		}
+
+		if result.Z != tc.expected || result.Z64 != complex64(tc.expected) {
+			t.Fatalf("%#v: bad: %#v", tc.input, result)
+		}
+	}
+}
+
+func TestDecode_complexErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input    interface{}
+		expected string
+	}{
+		{"1+2i", "'z' expected type 'complex128', got unconvertible type 'string'"},
+		{[]interface{}{1, 2, 3}, "'z' expected the real and imaginary parts, got 3 element(s)"},
+		{[]interface{}{1, "x"}, "'z[1]' expected type 'float64'"},
+		{map[string]interface{}{"real": "x"}, "'z.real' expected type 'float64'"},
+	}
+
+	for _, tc := range cases {
+		var result Complex
+		err := Decode(map[string]interface{}{"z": tc.input}, &result)
+		if err == nil {
+			t.Fatalf("%#v: should error", tc.input)
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Fatalf("%#v: bad: %s", tc.input, err)
+		}
+	}
+}
+
+func TestDecode_complexText(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input    string
+		expected complex128
+	}{
+		{"1+2i", 1 + 2i},
+		{"(1-2i)", 1 - 2i},
+		{"-3.5i", -3.5i},
+		{"i", 1i},
+		{"-i", -1i},
+		{"4", 4},
+		{"1e3-2.5e-1i", 1000 - 0.25i},
+		{"-1e+2+1e+2i", -100 + 100i},
+	}
+
+	for _, tc := range cases {
+		var result Complex
+		if err := DecodeForm(url.Values{"z": {tc.input}}, &result); err != nil {
+			t.Fatalf("%s: err: %s", tc.input, err)
+		}
+		if result.Z != tc.expected {
+			t.Fatalf("%s: bad: %#v", tc.input, result.Z)
+		}
+	}
+
+	var result Complex
+	err := DecodeForm(url.Values{"z": {"1+2j"}}, &result)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "cannot parse 'z' as complex") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestDecoder_ComplexFormat(t *testing.T) {
+	t.Parallel()
+
+	input := Complex{Z: 1 + 2i, Z64: -3i}
+
+	cases := []struct {
+		format   ComplexFormat
+		expected map[string]interface{}
+	}{
+		{
+			ComplexNative,
+			map[string]interface{}{"z": 1 + 2i, "z64": complex64(-3i)},
+		},
+		{
+			ComplexString,
+			map[string]interface{}{"z": "1+2i", "z64": "0-3i"},
+		},
+		{
+			ComplexPair,
+			map[string]interface{}{
+				"z":   []interface{}{1.0, 2.0},
+				"z64": []interface{}{0.0, -3.0},
+			},
+		},
+		{
+			ComplexMap,
+			map[string]interface{}{
+				"z":   map[string]interface{}{"real": 1.0, "imag": 2.0},
+				"z64": map[string]interface{}{"real": 0.0, "imag": -3.0},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		var result map[string]interface{}
+		decoder := &Decoder{Result: &result, ComplexFormat: tc.format}
+		if err := decoder.Decode(input); err != nil {
+			t.Fatalf("%d: err: %s", tc.format, err)
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Fatalf("%d: bad: %#v", tc.format, result)
+		}
+
+		// Only text input such as forms is parsed as a complex string.
+		if tc.format == ComplexString {
+			continue
+		}
+
+		var roundTrip Complex
+		if err := Decode(result, &roundTrip); err != nil {
+			t.Fatalf("%d: err: %s", tc.format, err)
+		}
+		if roundTrip != input {
+			t.Fatalf("%d: bad: %#v", tc.format, roundTrip)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package mapstructure
+
+import "reflect"
+
+// deepCopy returns a settable deep copy of v. Pointers, maps, slices and
+// interfaces are copied recursively, while unexported struct fields are
+// copied shallowly. Values reachable through several paths are copied
+// once, so aliasing and cycles are kept in the copy.
+func deepCopy(v reflect.Value) reflect.Value {
+	return copyValue(v, make(map[visitKey]reflect.Value))
+}
+
+// copyRoot returns a copy of the value the pointer root points to, made
+// through root so that pointers back to it point to the copy as well.
+// The copy is stored with storeRoot.
+func copyRoot(root reflect.Value) reflect.Value {
+	return deepCopy(root).Elem()
+}
+
+// storeRoot stores work, made by copyRoot, into the value root points to.
+// Pointers back to work, from a cycle through the root or one reproduced
+// by PreserveCycles, are made to point to root.
+func storeRoot(root, work reflect.Value) {
+	key, _ := inputVisitKey(work.Addr())
+	root.Elem().Set(copyValue(work, map[visitKey]reflect.Value{key: root}))
+}
+
+// copyValue copies v, looking up pointers, maps and slices that were
+// already copied in copies.
+func copyValue(v reflect.Value, copies map[visitKey]reflect.Value) reflect.Value {
+	result := reflect.New(v.Type()).Elem()
+
+	key, shared := inputVisitKey(v)
+	if shared {
+		if c, ok := copies[key]; ok {
+			result.Set(c)
+			return result
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			ptr := reflect.New(v.Type().Elem())
+			copies[key] = ptr
+			ptr.Elem().Set(copyValue(v.Elem(), copies))
+			result.Set(ptr)
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			result.Set(copyValue(v.Elem(), copies))
+		}
+	case reflect.Struct:
+		result.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := result.Field(i); field.CanSet() {
+				field.Set(copyValue(v.Field(i), copies))
+			}
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			result.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Cap()))
+			if shared {
+				copies[key] = result
+			}
+			for i := 0; i < v.Len(); i++ {
+				result.Index(i).Set(copyValue(v.Index(i), copies))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(copyValue(v.Index(i), copies))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			result.Set(reflect.MakeMapWithSize(v.Type(), v.Len()))
+			copies[key] = result
+			for _, k := range v.MapKeys() {
+				result.SetMapIndex(k, copyValue(v.MapIndex(k), copies))
+			}
+		}
+	default:
+		result.Set(v)
+	}
+
+	return result
+}
@@ -0,0 +1,140 @@
+package mapstructure
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// errStopReading is returned to readCSV to stop at the error limit.
+var errStopReading = errors.New("stop reading")
+
+// DecodeCSV reads every record from r and decodes it into output, which
+// must be a pointer to a slice. The first record is the header row and
+// its columns are the keys each record is decoded with, using the same
+// name matching as for maps. Cells are strings, so they are parsed into
+// numeric and boolean fields; empty cells leave the field untouched.
+//
+// Records that fail to decode are still appended so the slice index
+// always corresponds to the record, and all errors are returned together.
+// With an error limit, reading stops once the limit is reached.
+func DecodeCSV(r *csv.Reader, output interface{}) error {
+	val := reflect.ValueOf(output)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Slice {
+		return errors.New("result must be a pointer to a slice")
+	}
+
+	return (&Decoder{Result: output}).DecodeCSV(r)
+}
+
+// DecodeCSV decodes every record read from r into the slice pointed to
+// by the configuration.
+func (d *Decoder) DecodeCSV(r *csv.Reader) error {
+	sliceVal := reflect.ValueOf(d.Result).Elem()
+	elemType := sliceVal.Type().Elem()
+
+	errors := make([]string, 0)
+	truncated := false
+	err := readCSV(r, func(input map[string]interface{}) error {
+		if d.errorLimitReached(len(errors)) {
+			truncated = true
+			return errStopReading
+		}
+
+		elem := reflect.New(elemType)
+		sub := d.rowDecoder(elem.Interface(), len(errors))
+		err := sub.Decode(input)
+		d.Warnings = sub.Warnings
+		if err != nil {
+			errors = appendErrors(errors, err)
+			truncated = rowTruncated(err)
+		}
+
+		sliceVal.Set(reflect.Append(sliceVal, elem.Elem()))
+		return nil
+	})
+	if err != nil && err != errStopReading {
+		return err
+	}
+
+	return rowErrors(errors, truncated)
+}
+
+// StreamCSV reads the records from r one at a time. Each record is
+// decoded into a fresh zero value of the type output points to, which is
+// stored in output before fn is called. Streaming stops at the first
+// decoding error or at the first error returned by fn.
+func StreamCSV(r *csv.Reader, output interface{}, fn func() error) error {
+	val := reflect.ValueOf(output)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("result must be a non-nil pointer")
+	}
+
+	return (&Decoder{Result: output}).StreamCSV(r, fn)
+}
+
+// StreamCSV decodes the records read from r one at a time into the target
+// pointer specified by the configuration, calling fn after each one.
+func (d *Decoder) StreamCSV(r *csv.Reader, fn func() error) error {
+	val := reflect.ValueOf(d.Result).Elem()
+	return readCSV(r, func(input map[string]interface{}) error {
+		val.Set(reflect.Zero(val.Type()))
+		if err := d.Decode(input); err != nil {
+			return err
+		}
+
+		return fn()
+	})
+}
+
+// readCSV reads the header row from r and calls fn with every following
+// record as a map from column name to cell.
+func readCSV(r *csv.Reader, fn func(map[string]interface{}) error) error {
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		if j, ok := columns[column]; ok {
+			return fmt.Errorf("csv header has duplicate column '%s' at %d and %d", column, j+1, i+1)
+		}
+		columns[column] = i
+	}
+
+	for row := 2; ; row++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		input := make(map[string]interface{}, len(record))
+		for i, cell := range record {
+			if i >= len(header) {
+				return fmt.Errorf("csv row %d has more columns than the header", row)
+			}
+			if cell == "" {
+				continue
+			}
+
+			input[header[i]] = textValue{
+				key:    header[i],
+				pos:    fmt.Sprintf("row %d, column %d", row, i+1),
+				values: []string{cell},
+			}
+		}
+
+		if err := fn(input); err != nil {
+			return err
+		}
+	}
+}
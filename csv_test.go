@@ -0,0 +1,141 @@
+package mapstructure
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type CSVPerson struct {
+	Name   string
+	Age    int     `json:"age"`
+	Score  float64 `json:"score"`
+	Active bool
+}
+
+func TestDecodeCSV(t *testing.T) {
+	t.Parallel()
+
+	input := "name,age,score,ACTIVE,unknown\n" +
+		"alice,30,1.5,true,x\n" +
+		"bob,,2,false,y\n"
+
+	var result []CSVPerson
+	if err := DecodeCSV(csv.NewReader(strings.NewReader(input)), &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []CSVPerson{
+		{Name: "alice", Age: 30, Score: 1.5, Active: true},
+		{Name: "bob", Score: 2},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecodeCSV_Errors(t *testing.T) {
+	t.Parallel()
+
+	input := "name,age\n" +
+		"alice,thirty\n" +
+		"bob,40\n"
+
+	var result []CSVPerson
+	err := DecodeCSV(csv.NewReader(strings.NewReader(input)), &result)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if !strings.Contains(err.Error(), "row 2, column 2 'age'") {
+		t.Errorf("bad error: %s", err)
+	}
+
+	if len(result) != 2 || result[1].Age != 40 {
+		t.Errorf("bad: %#v", result)
+	}
+}
+
+func TestDecodeCSV_MaxErrors(t *testing.T) {
+	t.Parallel()
+
+	input := "name,age,score\n" +
+		"alice,thirty,x\n" +
+		"bob,forty,2\n" +
+		"carol,fifty,3\n"
+
+	var result []CSVPerson
+	decoder := &Decoder{Result: &result, MaxErrors: 3}
+	err := decoder.DecodeCSV(csv.NewReader(strings.NewReader(input)))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	derr := err.(*Error)
+	if !derr.Truncated || len(derr.Errors) != 3 {
+		t.Fatalf("bad: %#v", derr)
+	}
+	if len(result) != 2 {
+		t.Fatalf("reading should stop at the error limit: %#v", result)
+	}
+
+	// Reaching the limit at the last record is not truncated.
+	input = "name,age\n" +
+		"alice,30\n" +
+		"bob,forty\n"
+
+	result = nil
+	decoder = &Decoder{Result: &result, MaxErrors: 1}
+	err = decoder.DecodeCSV(csv.NewReader(strings.NewReader(input)))
+	if derr, ok := err.(*Error); !ok || derr.Truncated || len(derr.Errors) != 1 {
+		t.Fatalf("bad: %#v", err)
+	}
+}
+
+func TestDecodeCSV_DuplicateColumn(t *testing.T) {
+	t.Parallel()
+
+	var result []CSVPerson
+	err := DecodeCSV(csv.NewReader(strings.NewReader("age,age\n1,2\n")), &result)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestStreamCSV(t *testing.T) {
+	t.Parallel()
+
+	input := "name,age\n" +
+		"alice,30\n" +
+		"bob\n" +
+		"carol,50\n"
+	r := csv.NewReader(strings.NewReader(input))
+	r.FieldsPerRecord = -1
+
+	var names []string
+	var ages []int
+	var person CSVPerson
+	stop := errors.New("stop")
+	err := StreamCSV(r, &person, func() error {
+		names = append(names, person.Name)
+		ages = append(ages, person.Age)
+		if person.Name == "bob" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("err: %v", err)
+	}
+
+	if !reflect.DeepEqual(names, []string{"alice", "bob"}) {
+		t.Errorf("bad: %#v", names)
+	}
+
+	// The second record must not keep the age of the first one.
+	if !reflect.DeepEqual(ages, []int{30, 0}) {
+		t.Errorf("bad: %#v", ages)
+	}
+}
@@ -0,0 +1,87 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// visitKey identifies a pointer, map or slice of the input. Slices also
+// need their length, as a slice and a shorter one of the same array are
+// different values.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// visit is an input value that is being decoded, with its path and the
+// output value it is being decoded into.
+type visit struct {
+	name string
+	val  reflect.Value
+}
+
+// inputVisitKey returns the key of a non-nil pointer, map or slice.
+func inputVisitKey(v reflect.Value) (visitKey, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if !v.IsNil() {
+			return visitKey{ptr: v.Pointer(), typ: v.Type()}, true
+		}
+	case reflect.Slice:
+		if !v.IsNil() && v.Len() > 0 {
+			return visitKey{ptr: v.Pointer(), typ: v.Type(), len: v.Len()}, true
+		}
+	}
+
+	return visitKey{}, false
+}
+
+// enterVisit records that the input value is being decoded into val, or
+// returns an error if it already is, which means the input has a cycle.
+// The returned function must be called once the input is done.
+//
+// Only values decoded into a struct, map, slice or array are recorded, as
+// the decoder passes the same input on unchanged when decoding into a
+// pointer or interface.
+func (d *Decoder) enterVisit(name string, input reflect.Value, val reflect.Value) (func(), error) {
+	leave := func() {}
+
+	switch getKind(val) {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return leave, nil
+	}
+
+	key, ok := inputVisitKey(input)
+	if !ok {
+		return leave, nil
+	}
+
+	if v, ok := d.visiting[key]; ok {
+		return leave, fmt.Errorf("cycle detected at '%s', back to '%s'", name, v.name)
+	}
+
+	if d.visiting == nil {
+		d.visiting = make(map[visitKey]visit)
+	}
+	d.visiting[key] = visit{name: name, val: val}
+	return func() { delete(d.visiting, key) }, nil
+}
+
+// visitedPtr returns a pointer to the output value that the input pointer
+// data is already being decoded into, so a cycle through it can be
+// reproduced in the output.
+func (d *Decoder) visitedPtr(data interface{}, ptrType reflect.Type) (reflect.Value, bool) {
+	key, ok := inputVisitKey(reflect.ValueOf(data))
+	if !ok || key.typ.Kind() != reflect.Ptr {
+		return reflect.Value{}, false
+	}
+
+	v, ok := d.visiting[key]
+	if !ok || !v.val.CanAddr() || v.val.Addr().Type() != ptrType {
+		return reflect.Value{}, false
+	}
+
+	return v.val.Addr(), true
+}
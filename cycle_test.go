@@ -0,0 +1,147 @@
+package mapstructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type CycleNode struct {
+	Name string
+	Next *CycleNode
+}
+
+type CycleNodeOut struct {
+	Name string
+	Next *CycleNodeOut
+}
+
+func TestDecode_cycle(t *testing.T) {
+	t.Parallel()
+
+	a := &CycleNode{Name: "a"}
+	b := &CycleNode{Name: "b", Next: a}
+	a.Next = b
+
+	var result CycleNodeOut
+	err := Decode(a, &result)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "cycle detected at 'Next.Next', back to ''") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestDecode_cycleMap(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{"Name": "a"}
+	input["Next"] = input
+
+	var result CycleNodeOut
+	err := Decode(input, &result)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "cycle detected at 'Next', back to ''") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	// Decoding into an interface keeps the input as it is, so there is
+	// nothing to walk endlessly.
+	var iface interface{}
+	if err := Decode(input, &iface); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestDecode_sharedIsNotCycle(t *testing.T) {
+	t.Parallel()
+
+	type Pair struct {
+		Left  *CycleNode
+		Right *CycleNode
+	}
+
+	type PairOut struct {
+		Left  *CycleNodeOut
+		Right *CycleNodeOut
+	}
+
+	shared := &CycleNode{Name: "shared"}
+
+	var result PairOut
+	if err := Decode(Pair{Left: shared, Right: shared}, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := PairOut{
+		Left:  &CycleNodeOut{Name: "shared"},
+		Right: &CycleNodeOut{Name: "shared"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecoder_PreserveCycles(t *testing.T) {
+	t.Parallel()
+
+	a := &CycleNode{Name: "a"}
+	b := &CycleNode{Name: "b", Next: a}
+	a.Next = b
+
+	var result CycleNodeOut
+	decoder := &Decoder{Result: &result, PreserveCycles: true}
+	if err := decoder.Decode(a); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.Name != "a" || result.Next.Name != "b" {
+		t.Fatalf("bad: %#v", result)
+	}
+	if result.Next.Next != &result {
+		t.Fatalf("bad: %#v", result.Next.Next)
+	}
+
+	self := &CycleNode{Name: "self"}
+	self.Next = self
+
+	var out *CycleNodeOut
+	decoder = &Decoder{Result: &out, PreserveCycles: true}
+	if err := decoder.Decode(self); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if out.Name != "self" || out.Next != out {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
+func TestDecoder_PreserveCyclesAtomic(t *testing.T) {
+	t.Parallel()
+
+	a := &CycleNode{Name: "a"}
+	a.Next = &CycleNode{Name: "b", Next: a}
+
+	var result CycleNodeOut
+	decoder := &Decoder{Result: &result, PreserveCycles: true, Atomic: true}
+	if err := decoder.Decode(a); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.Name != "a" || result.Next.Name != "b" {
+		t.Fatalf("bad: %#v", result)
+	}
+	if result.Next.Next != &result {
+		t.Fatalf("bad: %#v", result.Next.Next)
+	}
+
+	// A cycle through the root of Result is kept by later decodes.
+	if err := decoder.Decode(map[string]interface{}{"Name": "c"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result.Name != "c" || result.Next.Next != &result {
+		t.Fatalf("bad: %#v", result)
+	}
+}
@@ -0,0 +1,231 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeKind is the kind of a Change.
+type ChangeKind uint8
+
+const (
+	// Added means the value is only present in the new value.
+	Added ChangeKind = iota + 1
+
+	// Removed means the value is only present in the old value.
+	Removed
+
+	// Modified means the value is present in both but differs.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", uint8(k))
+	}
+}
+
+// Change describes a single difference found by Diff.
+type Change struct {
+	// Path is the path of the changed value, in the same format as used
+	// in errors, e.g. "servers[0].port" or "labels[env]".
+	Path string
+
+	Kind ChangeKind
+
+	// Old and New are the values before and after the change. Old is nil
+	// for Added and New is nil for Removed.
+	Old interface{}
+	New interface{}
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s '%s': %v -> %v", c.Kind, c.Path, c.Old, c.New)
+}
+
+// Diff compares a and b, which are usually two versions of the same
+// configuration struct, and returns every changed value in a stable
+// order.
+//
+// Structs are walked field by field, naming them by their tag or field
+// name like the decoder does, maps are compared key by key and slices
+// index by index. Nil pointers and empty maps and slices count as absent,
+// so a value changing from or to nil is Added or Removed. Values of
+// different types, and values such as time.Time that cannot be walked,
+// are reported as Modified as a whole.
+func Diff(a, b interface{}) []Change {
+	var changes []Change
+	diffValues(&changes, make(map[diffPair]struct{}), "", reflect.ValueOf(a), reflect.ValueOf(b))
+	return changes
+}
+
+// diffPair identifies two pointers, maps or slices being compared. Pairs
+// are only tracked while they are being walked, so cycles end while
+// values shared at several paths are still reported at each of them.
+type diffPair struct {
+	a, b visitKey
+}
+
+func diffValues(changes *[]Change, seen map[diffPair]struct{}, path string, a, b reflect.Value) {
+	if pair, ok := newDiffPair(a, b); ok {
+		if _, ok := seen[pair]; ok {
+			return
+		}
+		seen[pair] = struct{}{}
+		defer delete(seen, pair)
+	}
+
+	a, b = diffIndirect(a), diffIndirect(b)
+
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return
+	case !a.IsValid():
+		*changes = append(*changes, Change{Path: path, Kind: Added, New: b.Interface()})
+		return
+	case !b.IsValid():
+		*changes = append(*changes, Change{Path: path, Kind: Removed, Old: a.Interface()})
+		return
+	case a.Type() != b.Type():
+		*changes = append(*changes, Change{Path: path, Kind: Modified, Old: a.Interface(), New: b.Interface()})
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Struct:
+		if a.Type().Implements(textMarshalerType) || !hasExportedFields(a.Type()) {
+			break
+		}
+
+		typ := a.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+
+			keyName := f.Name
+			if tagValue := strings.SplitN(f.Tag.Get(tagName), ",", 2)[0]; tagValue != "" {
+				if tagValue == "-" {
+					continue
+				}
+				keyName = tagValue
+			}
+			if path != "" {
+				keyName = fmt.Sprintf("%s.%s", path, keyName)
+			}
+
+			diffValues(changes, seen, keyName, a.Field(i), b.Field(i))
+		}
+		return
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range a.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, k := range b.MapKeys() {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+
+		names := make([]string, 0, len(keys))
+		for name := range keys {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			k := keys[name]
+			diffValues(changes, seen, fmt.Sprintf("%s[%s]", path, name), a.MapIndex(k), b.MapIndex(k))
+		}
+		return
+	case reflect.Slice, reflect.Array:
+		n := a.Len()
+		if b.Len() > n {
+			n = b.Len()
+		}
+
+		for i := 0; i < n; i++ {
+			var ai, bi reflect.Value
+			if i < a.Len() {
+				ai = a.Index(i)
+			}
+			if i < b.Len() {
+				bi = b.Index(i)
+			}
+			diffValues(changes, seen, fmt.Sprintf("%s[%d]", path, i), ai, bi)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		*changes = append(*changes, Change{Path: path, Kind: Modified, Old: a.Interface(), New: b.Interface()})
+	}
+}
+
+// newDiffPair returns the pair of a and b if both are non-nil pointers,
+// maps or slices of the same type.
+func newDiffPair(a, b reflect.Value) (diffPair, bool) {
+	for a.IsValid() && a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.IsValid() && b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return diffPair{}, false
+	}
+
+	ak, ok := inputVisitKey(a)
+	if !ok {
+		return diffPair{}, false
+	}
+	bk, ok := inputVisitKey(b)
+	if !ok {
+		return diffPair{}, false
+	}
+
+	return diffPair{a: ak, b: bk}, true
+}
+
+// diffIndirect dereferences pointers and interfaces and returns the
+// invalid Value for nil ones, as well as for empty maps and slices.
+func diffIndirect(v reflect.Value) reflect.Value {
+	for v.IsValid() {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		case reflect.Map, reflect.Slice:
+			if v.Len() == 0 {
+				return reflect.Value{}
+			}
+			return v
+		default:
+			return v
+		}
+	}
+
+	return v
+}
+
+func hasExportedFields(typ reflect.Type) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+
+	return false
+}
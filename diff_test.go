@@ -0,0 +1,128 @@
+package mapstructure
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type DiffServer struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type DiffConfig struct {
+	Basic
+	Name     string            `json:"name"`
+	Servers  []DiffServer      `json:"servers"`
+	Labels   map[string]string `json:"labels"`
+	Backup   *DiffServer       `json:"backup"`
+	Started  time.Time         `json:"started"`
+	Internal string            `json:"-"`
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := DiffConfig{
+		Basic: Basic{VString: "foo"},
+		Name:  "old",
+		Servers: []DiffServer{
+			{Host: "a", Port: 1},
+			{Host: "b", Port: 2},
+		},
+		Labels:   map[string]string{"env": "dev", "team": "x"},
+		Backup:   &DiffServer{Host: "backup"},
+		Internal: "a",
+	}
+	b := DiffConfig{
+		Basic: Basic{VString: "bar"},
+		Name:  "old",
+		Servers: []DiffServer{
+			{Host: "a", Port: 10},
+		},
+		Labels:   map[string]string{"env": "prod", "owner": "y"},
+		Started:  started,
+		Internal: "b",
+	}
+
+	expected := []Change{
+		{Path: "Basic.VString", Kind: Modified, Old: "foo", New: "bar"},
+		{Path: "servers[0].port", Kind: Modified, Old: 1, New: 10},
+		{Path: "servers[1]", Kind: Removed, Old: DiffServer{Host: "b", Port: 2}},
+		{Path: "labels[env]", Kind: Modified, Old: "dev", New: "prod"},
+		{Path: "labels[owner]", Kind: Added, New: "y"},
+		{Path: "labels[team]", Kind: Removed, Old: "x"},
+		{Path: "backup", Kind: Removed, Old: DiffServer{Host: "backup"}},
+		{Path: "started", Kind: Modified, Old: time.Time{}, New: started},
+	}
+
+	changes := Diff(a, &b)
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("bad: %#v", changes)
+	}
+
+	if changes := Diff(a, a); len(changes) != 0 {
+		t.Fatalf("bad: %#v", changes)
+	}
+}
+
+func TestDiff_Maps(t *testing.T) {
+	t.Parallel()
+
+	a := map[string]interface{}{
+		"port": 80,
+		"tags": []interface{}{"a"},
+	}
+	b := map[string]interface{}{
+		"port": "80",
+		"tags": []interface{}{},
+	}
+
+	expected := []Change{
+		{Path: "[port]", Kind: Modified, Old: 80, New: "80"},
+		{Path: "[tags]", Kind: Removed, Old: []interface{}{"a"}},
+	}
+
+	changes := Diff(a, b)
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("bad: %#v", changes)
+	}
+
+	if s := changes[0].String(); s != "modified '[port]': 80 -> 80" {
+		t.Fatalf("bad: %s", s)
+	}
+}
+
+func TestDiff_Cycles(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name string `json:"name"`
+		Next *Node  `json:"next"`
+	}
+
+	a := &Node{Name: "a"}
+	a.Next = &Node{Name: "b", Next: a}
+	b := &Node{Name: "a"}
+	b.Next = &Node{Name: "b", Next: b}
+
+	if changes := Diff(a, b); len(changes) != 0 {
+		t.Fatalf("bad: %#v", changes)
+	}
+
+	b.Next.Name = "c"
+	expected := []Change{
+		{Path: "next.name", Kind: Modified, Old: "b", New: "c"},
+	}
+	if changes := Diff(a, b); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("bad: %#v", changes)
+	}
+
+	m := map[string]interface{}{"name": "m"}
+	m["self"] = m
+	if changes := Diff(m, map[string]interface{}{"name": "m", "self": m}); len(changes) != 0 {
+		t.Fatalf("bad: %#v", changes)
+	}
+}
@@ -10,6 +10,10 @@ import (
 // errors that occur in the course of a single decode.
 type Error struct {
 	Errors []string
+
+	// Truncated is set if decoding stopped early because the error limit
+	// of the Decoder was reached, so the input may have more errors.
+	Truncated bool
 }
 
 func (e *Error) Error() string {
@@ -19,6 +23,12 @@ func (e *Error) Error() string {
 	}
 
 	sort.Strings(points)
+	if e.Truncated {
+		return fmt.Sprintf(
+			"%d error(s) decoding, stopped early:\n\n%s",
+			len(e.Errors), strings.Join(points, "\n"))
+	}
+
 	return fmt.Sprintf(
 		"%d error(s) decoding:\n\n%s",
 		len(e.Errors), strings.Join(points, "\n"))
@@ -32,3 +42,105 @@ func appendErrors(errors []string, err error) []string {
 		return append(errors, e.Error())
 	}
 }
+
+// appendErrors appends err to errors like the function of the same name
+// and counts it towards the error limit. Nested *Error values are not
+// counted again, as their errors were counted where they were found.
+func (d *Decoder) appendErrors(errors []string, err error) []string {
+	if _, ok := err.(*Error); !ok {
+		d.errorCount++
+	}
+
+	return appendErrors(errors, err)
+}
+
+func (d *Decoder) errorLimit() int {
+	if d.FailFast {
+		return 1
+	}
+
+	return d.MaxErrors
+}
+
+// errorLimitReached reports whether count errors reach the error limit.
+func (d *Decoder) errorLimitReached(count int) bool {
+	limit := d.errorLimit()
+	return limit > 0 && count >= limit
+}
+
+// tooManyErrors reports whether decoding should stop because the error
+// limit was reached.
+func (d *Decoder) tooManyErrors() bool {
+	return d.errorLimitReached(d.errorCount)
+}
+
+// stopped reports whether decoding should stop, because the error limit
+// was reached or the input exceeded one of the Limits.
+func (d *Decoder) stopped() bool {
+	return d.limitErr != nil || d.tooManyErrors()
+}
+
+// stopLoop is stopped for loops over the input that have more to decode,
+// and records that the errors are truncated if the loop stops.
+func (d *Decoder) stopLoop() bool {
+	if !d.stopped() {
+		return false
+	}
+
+	d.truncated = true
+	return true
+}
+
+// decodeErrors returns the error for Decode. An exceeded limit is returned
+// on its own, and an *Error is cut down to the error limit and marked as
+// truncated if decoding stopped before the end of the input.
+func (d *Decoder) decodeErrors(err error) error {
+	if d.limitErr != nil {
+		return d.limitErr
+	}
+
+	e, ok := err.(*Error)
+	if !ok {
+		return err
+	}
+
+	errors, truncated := e.Errors, d.truncated
+	if limit := d.errorLimit(); limit > 0 && len(errors) > limit {
+		errors, truncated = errors[:limit], true
+	}
+	if !truncated {
+		return err
+	}
+
+	return &Error{Errors: errors, Truncated: true}
+}
+
+// rowDecoder returns a copy of d that decodes a single row or record into
+// result, for the decoders of many rows. The error limit of d spans all
+// rows, so the copy only allows the errors left after errorCount.
+func (d *Decoder) rowDecoder(result interface{}, errorCount int) *Decoder {
+	sub := *d
+	sub.Result = result
+	if limit := d.errorLimit(); limit > 0 {
+		sub.FailFast, sub.MaxErrors = false, limit-errorCount
+	}
+
+	return &sub
+}
+
+// rowErrors returns the errors collected from decoding rows. truncated
+// is whether a row or the rows after the last one decoded were skipped.
+func rowErrors(errors []string, truncated bool) error {
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return &Error{Errors: errors, Truncated: truncated}
+}
+
+// rowTruncated reports whether err is an *Error of a row that stopped
+// early.
+func rowTruncated(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Truncated
+}
@@ -0,0 +1,223 @@
+package mapstructure
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const usageTagName = `usage`
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// RegisterFlags walks the struct pointed to by config and defines a flag
+// on fs for every exported scalar field. Nested structs are flattened into
+// dotted flag names such as "server.port". The current field values are
+// used as the flag defaults and the "usage" tag as the help text.
+func RegisterFlags(fs *flag.FlagSet, config interface{}) error {
+	return (&Decoder{}).RegisterFlags(fs, config)
+}
+
+// RegisterFlags is like the RegisterFlags function, but names the flags of
+// untagged fields with the NamingStrategy of the configuration, so they
+// match the keys DecodeFlags looks up.
+func (d *Decoder) RegisterFlags(fs *flag.FlagSet, config interface{}) error {
+	val := reflect.ValueOf(config)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("config must be a non-nil pointer")
+	}
+
+	val = val.Elem()
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("config must point to a struct, got '%s'", val.Kind())
+	}
+
+	return d.registerStructFlags(fs, "", val)
+}
+
+func (d *Decoder) registerStructFlags(fs *flag.FlagSet, prefix string, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tagValue := strings.SplitN(f.Tag.Get(tagName), ",", 2)[0]
+		if tagValue == "-" {
+			continue
+		}
+
+		// Untagged fields are matched case-insensitively by the decoder, so
+		// the lower case field name is the friendliest flag name, unless a
+		// naming strategy decides the key.
+		flagName := strings.ToLower(f.Name)
+		if d.NamingStrategy != nil {
+			flagName = d.fieldKey(f.Name)
+		}
+		if tagValue != "" {
+			flagName = tagValue
+		}
+		if prefix != "" {
+			flagName = fmt.Sprintf("%s.%s", prefix, flagName)
+		}
+
+		fieldVal := val.Field(i)
+		if fieldVal.Kind() == reflect.Ptr {
+			if fieldVal.IsNil() {
+				fieldVal = reflect.Zero(fieldVal.Type().Elem())
+			} else {
+				fieldVal = fieldVal.Elem()
+			}
+		}
+
+		switch getKind(fieldVal) {
+		case reflect.Struct:
+			if err := d.registerStructFlags(fs, flagName, fieldVal); err != nil {
+				return err
+			}
+		case reflect.Bool, reflect.String, reflect.Int, reflect.Uint, reflect.Float32:
+			if fs.Lookup(flagName) != nil {
+				return fmt.Errorf("flag '%s' is already defined", flagName)
+			}
+
+			value := &flagValue{typ: fieldVal.Type(), value: fieldVal.Interface()}
+			fs.Var(value, flagName, f.Tag.Get(usageTagName))
+		}
+	}
+
+	return nil
+}
+
+// DecodeFlags decodes the flags that were explicitly set when fs was
+// parsed into output. Flags left at their defaults are not decoded, so
+// the result can be layered on top of configuration from another source.
+func DecodeFlags(fs *flag.FlagSet, output interface{}) error {
+	val := reflect.ValueOf(output)
+	if val.Kind() != reflect.Ptr {
+		return errors.New("result must be a pointer")
+	}
+
+	if !val.Elem().CanAddr() {
+		return errors.New("result must be addressable (a pointer)")
+	}
+
+	return (&Decoder{Result: output}).DecodeFlags(fs)
+}
+
+// DecodeFlags decodes the flags that were explicitly set when fs was
+// parsed to the target pointer specified by the configuration.
+func (d *Decoder) DecodeFlags(fs *flag.FlagSet) error {
+	if !fs.Parsed() {
+		return errors.New("flag set must be parsed before decoding")
+	}
+
+	input := make(map[string]interface{})
+	errors := make([]string, 0)
+	fs.Visit(func(f *flag.Flag) {
+		var value interface{}
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value = getter.Get()
+		} else {
+			value = f.Value.String()
+		}
+
+		if err := setFlagInput(input, f.Name, value); err != nil {
+			errors = appendErrors(errors, err)
+		}
+	})
+
+	if len(errors) > 0 {
+		return &Error{Errors: errors}
+	}
+
+	return d.Decode(input)
+}
+
+// setFlagInput stores value in input under the dotted flag name, creating
+// the intermediate maps for every segment of the name.
+func setFlagInput(input map[string]interface{}, name string, value interface{}) error {
+	parts := strings.Split(name, ".")
+	current := input
+	for i, part := range parts[:len(parts)-1] {
+		switch next := current[part].(type) {
+		case nil:
+			m := make(map[string]interface{})
+			current[part] = m
+			current = m
+		case map[string]interface{}:
+			current = next
+		default:
+			return fmt.Errorf(
+				"flag '%s' conflicts with flag '%s'",
+				name, strings.Join(parts[:i+1], "."))
+		}
+	}
+
+	last := parts[len(parts)-1]
+	if _, ok := current[last]; ok {
+		return fmt.Errorf("flag '%s' conflicts with a nested flag", name)
+	}
+
+	current[last] = value
+	return nil
+}
+
+// flagValue is a flag.Value that parses its argument according to the
+// type of the struct field it was registered for.
+type flagValue struct {
+	typ   reflect.Type
+	value interface{}
+}
+
+func (v *flagValue) String() string {
+	if v == nil || v.value == nil {
+		return ""
+	}
+
+	return fmt.Sprint(v.value)
+}
+
+func (v *flagValue) Set(s string) error {
+	value, err := parseFlagValue(v.typ, s)
+	if err != nil {
+		return err
+	}
+
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) Get() interface{} {
+	return v.value
+}
+
+// IsBoolFlag allows boolean flags to be given without an explicit value.
+func (v *flagValue) IsBoolFlag() bool {
+	return v.typ != nil && v.typ.Kind() == reflect.Bool
+}
+
+func parseFlagValue(typ reflect.Type, s string) (interface{}, error) {
+	if typ == durationType {
+		return time.ParseDuration(s)
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(s)
+	case reflect.String:
+		return s, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(s, 0, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(s, 0, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(s, typ.Bits())
+	default:
+		return nil, fmt.Errorf("unsupported flag type: %s", typ)
+	}
+}
@@ -0,0 +1,167 @@
+package mapstructure
+
+import (
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type FlagServer struct {
+	Host    string `usage:"address to listen on"`
+	Port    int    `json:"port" usage:"port to listen on"`
+	Timeout time.Duration
+}
+
+type FlagConfig struct {
+	Name    string
+	Verbose bool
+	Ratio   float64
+	Server  FlagServer
+	Backup  *FlagServer
+	Skipped string `json:"-"`
+	Tags    []string
+}
+
+func newTestFlagSet(t *testing.T, config interface{}) *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	if err := RegisterFlags(fs, config); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	return fs
+}
+
+func TestRegisterFlags(t *testing.T) {
+	t.Parallel()
+
+	config := FlagConfig{Name: "default"}
+	fs := newTestFlagSet(t, &config)
+
+	expected := []string{
+		"backup.host", "backup.port", "backup.timeout",
+		"name", "ratio",
+		"server.host", "server.port", "server.timeout",
+		"verbose",
+	}
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, f.Name)
+	})
+	if !reflect.DeepEqual(names, expected) {
+		t.Fatalf("bad: %#v", names)
+	}
+
+	if f := fs.Lookup("server.port"); f.Usage != "port to listen on" {
+		t.Errorf("bad usage: %q", f.Usage)
+	}
+
+	if f := fs.Lookup("name"); f.DefValue != "default" {
+		t.Errorf("bad default: %q", f.DefValue)
+	}
+}
+
+func TestDecodeFlags(t *testing.T) {
+	t.Parallel()
+
+	var config FlagConfig
+	fs := newTestFlagSet(t, &config)
+
+	args := []string{
+		"-verbose",
+		"-ratio", "0.5",
+		"--server.port", "8080",
+		"--server.timeout", "5s",
+		"--backup.host", "backup.local",
+	}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result := FlagConfig{
+		Name: "from file",
+		Server: FlagServer{
+			Host: "localhost",
+			Port: 80,
+		},
+	}
+	if err := DecodeFlags(fs, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := FlagConfig{
+		Name:    "from file",
+		Verbose: true,
+		Ratio:   0.5,
+		Server: FlagServer{
+			Host:    "localhost",
+			Port:    8080,
+			Timeout: 5 * time.Second,
+		},
+		Backup: &FlagServer{
+			Host: "backup.local",
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecodeFlags_NamingStrategy(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		MaxRetryCount int
+		Server        FlagServer
+	}
+
+	d := &Decoder{NamingStrategy: SnakeCase}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	if err := d.RegisterFlags(fs, &Config{}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	args := []string{"--max_retry_count", "3", "--server.timeout", "5s"}
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var result Config
+	d.Result = &result
+	if err := d.DecodeFlags(fs); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := Config{
+		MaxRetryCount: 3,
+		Server:        FlagServer{Timeout: 5 * time.Second},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecodeFlags_InvalidValue(t *testing.T) {
+	t.Parallel()
+
+	var config FlagConfig
+	fs := newTestFlagSet(t, &config)
+
+	if err := fs.Parse([]string{"--server.port", "http"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestDecodeFlags_NotParsed(t *testing.T) {
+	t.Parallel()
+
+	var config FlagConfig
+	fs := newTestFlagSet(t, &config)
+
+	if err := DecodeFlags(fs, &config); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,170 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unflatten turns a flat map with keys such as "db.host" into nested
+// maps by splitting every key on sep. Numeric segments such as the "0" in
+// "tags.0" index into slices, leaving gaps in sparse indexes as nil. An
+// index larger than the number of keys in input would allocate more than
+// the input warrants, so its parent is kept as a map instead.
+//
+// A key that is both a value and the parent of other keys, such as "db"
+// next to "db.host", or whose children mix indexes and names is reported
+// as a conflict. All conflicts are returned together.
+func Unflatten(input map[string]interface{}, sep string) (map[string]interface{}, error) {
+	root, err := unflatten(input, sep)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(root.entries))
+	for k, v := range root.entries {
+		if n, ok := v.(keyNode); ok {
+			v = n.finish()
+		}
+		result[k] = v
+	}
+
+	return result, nil
+}
+
+// unflatten builds the tree of keyNodes for Unflatten. Decoding the tree
+// directly lets numeric segments be map keys for map targets.
+func unflatten(input map[string]interface{}, sep string) (keyNode, error) {
+	if sep == "" {
+		return keyNode{}, fmt.Errorf("separator must not be empty")
+	}
+
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := keyNode{entries: make(map[string]interface{}), maxLen: len(input)}
+	leaves := make(map[string]string)
+	errors := make([]string, 0)
+	for _, key := range keys {
+		segments := strings.Split(key, sep)
+		if err := setFlatValue(root, leaves, key, sep, segments, input[key]); err != nil {
+			errors = appendErrors(errors, err)
+		}
+	}
+
+	if len(errors) > 0 {
+		return keyNode{}, &Error{Errors: errors}
+	}
+
+	return root, nil
+}
+
+// setFlatValue stores value in the tree under root at the given segments
+// of key. leaves records the key each leaf was set by, so conflicts can
+// name both keys.
+func setFlatValue(root keyNode, leaves map[string]string, key, sep string, segments []string, value interface{}) error {
+	node := root
+	for i, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("key '%s' has an empty segment", key)
+		}
+
+		path := strings.Join(segments[:i+1], sep)
+		if leaf, ok := leaves[path]; ok {
+			return fmt.Errorf("key '%s' conflicts with key '%s'", key, leaf)
+		}
+
+		// Below the root, the children of a node are either all indexes
+		// or all names.
+		if i > 0 {
+			for sibling := range node.entries {
+				if isIndexSegment(sibling) != isIndexSegment(segment) {
+					return fmt.Errorf("key '%s' mixes indexes and names at '%s'", key, segment)
+				}
+				break
+			}
+		}
+
+		next := node.entries[segment]
+		if i == len(segments)-1 {
+			if next != nil {
+				return fmt.Errorf("key '%s' conflicts with a nested key", key)
+			}
+			node.entries[segment] = value
+			leaves[path] = key
+			return nil
+		}
+
+		if next == nil {
+			next = keyNode{entries: make(map[string]interface{}), maxLen: root.maxLen}
+			node.entries[segment] = next
+		}
+		node = next.(keyNode)
+	}
+
+	return nil
+}
+
+// unflattenInput unflattens input for Decode if it is a map with string
+// keys, and returns any other input unchanged.
+func (d *Decoder) unflattenInput(input interface{}) (interface{}, error) {
+	val := reflect.ValueOf(input)
+	if val.Kind() != reflect.Map || val.Type().Key().Kind() != reflect.String {
+		return input, nil
+	}
+
+	flat, ok := input.(map[string]interface{})
+	if !ok {
+		flat = make(map[string]interface{}, val.Len())
+		for _, k := range val.MapKeys() {
+			flat[k.String()] = val.MapIndex(k).Interface()
+		}
+	}
+
+	return unflatten(flat, d.KeySeparator)
+}
+
+// Flatten is the inverse of Unflatten: it turns nested maps and slices
+// into a single map whose keys join the map keys and slice indexes of
+// every value with sep. Empty maps and slices are kept as values so they
+// are not lost.
+func Flatten(input map[string]interface{}, sep string) map[string]interface{} {
+	result := make(map[string]interface{})
+	flattenValue(result, "", sep, reflect.ValueOf(input))
+	return result
+}
+
+func flattenValue(result map[string]interface{}, prefix, sep string, val reflect.Value) {
+	join := func(key string) string {
+		if prefix == "" {
+			return key
+		}
+		return prefix + sep + key
+	}
+
+	for val.Kind() == reflect.Interface && !val.IsNil() {
+		val = val.Elem()
+	}
+
+	switch {
+	case !val.IsValid() || val.Kind() == reflect.Interface:
+		if prefix != "" {
+			result[prefix] = nil
+		}
+	case val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String && val.Len() > 0:
+		for _, k := range val.MapKeys() {
+			flattenValue(result, join(k.String()), sep, val.MapIndex(k))
+		}
+	case val.Kind() == reflect.Slice && val.Type().Elem().Kind() != reflect.Uint8 && val.Len() > 0:
+		for i := 0; i < val.Len(); i++ {
+			flattenValue(result, join(strconv.Itoa(i)), sep, val.Index(i))
+		}
+	case prefix != "":
+		result[prefix] = val.Interface()
+	}
+}
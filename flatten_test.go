@@ -0,0 +1,219 @@
+package mapstructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnflatten(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"db.host":         "x",
+		"db.port":         5432,
+		"tags.0":          "a",
+		"tags.2":          "c",
+		"servers.0.name":  "one",
+		"servers.1.name":  "two",
+		"servers.1.ports": []int{80},
+		"0":               "root",
+		"plain":           true,
+	}
+
+	result, err := Unflatten(input, ".")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "x",
+			"port": 5432,
+		},
+		"tags": []interface{}{"a", nil, "c"},
+		"servers": []interface{}{
+			map[string]interface{}{"name": "one"},
+			map[string]interface{}{"name": "two", "ports": []int{80}},
+		},
+		"0":     "root",
+		"plain": true,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestUnflatten_conflicts(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input    map[string]interface{}
+		expected string
+	}{
+		{
+			map[string]interface{}{"db": "x", "db.host": "y"},
+			"key 'db.host' conflicts with key 'db'",
+		},
+		{
+			map[string]interface{}{"db.host": "y", "db": "x"},
+			"key 'db.host' conflicts with key 'db'",
+		},
+		{
+			map[string]interface{}{"tags.0": "a", "tags.name": "b"},
+			"key 'tags.name' mixes indexes and names at 'name'",
+		},
+		{
+			map[string]interface{}{"db..host": "x"},
+			"key 'db..host' has an empty segment",
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := Unflatten(tc.input, ".")
+		if err == nil {
+			t.Fatalf("%#v: should error", tc.input)
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Fatalf("%#v: bad: %s", tc.input, err)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host": "x",
+			"port": 5432,
+		},
+		"tags":    []string{"a", "b"},
+		"labels":  map[string]string{"env": "prod"},
+		"empty":   map[string]interface{}{},
+		"missing": nil,
+		"data":    []byte("raw"),
+	}
+
+	result := Flatten(input, "_")
+	expected := map[string]interface{}{
+		"db_host":    "x",
+		"db_port":    5432,
+		"tags_0":     "a",
+		"tags_1":     "b",
+		"labels_env": "prod",
+		"empty":      map[string]interface{}{},
+		"missing":    nil,
+		"data":       []byte("raw"),
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	roundTrip, err := Unflatten(result, "_")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(roundTrip["db"], input["db"]) {
+		t.Fatalf("bad: %#v", roundTrip)
+	}
+}
+
+func TestDecoder_KeySeparator(t *testing.T) {
+	t.Parallel()
+
+	type DB struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+
+	type Config struct {
+		DB   DB       `json:"db"`
+		Tags []string `json:"tags"`
+	}
+
+	var result Config
+	decoder := &Decoder{Result: &result, KeySeparator: "__"}
+	err := decoder.Decode(map[string]interface{}{
+		"db__host": "x",
+		"db__port": 5432,
+		"tags__0":  "a",
+		"tags__1":  "b",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := Config{
+		DB:   DB{Host: "x", Port: 5432},
+		Tags: []string{"a", "b"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	var other Config
+	decoder = &Decoder{Result: &other, KeySeparator: "."}
+	err = decoder.Decode(map[string]string{"db.host": "y", "db": "z"})
+	if err == nil {
+		t.Fatal("should error")
+	}
+}
+
+func TestUnflatten_largeIndexes(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"tags.99999999999999999999": "a",
+		"ids.50000000":              1,
+	}
+
+	result, err := Unflatten(input, ".")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"tags": map[string]interface{}{"99999999999999999999": "a"},
+		"ids":  map[string]interface{}{"50000000": 1},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecoder_KeySeparatorMapKeys(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Ports map[string]string `json:"ports"`
+		Tags  []string          `json:"tags"`
+	}
+
+	var result Config
+	decoder := &Decoder{Result: &result, KeySeparator: "."}
+	err := decoder.Decode(map[string]interface{}{
+		"ports.80":  "http",
+		"ports.443": "https",
+		"tags.0":    "a",
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := Config{
+		Ports: map[string]string{"80": "http", "443": "https"},
+		Tags:  []string{"a"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	err = decoder.Decode(map[string]interface{}{"tags.50000000": "a"})
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "'tags' index 50000000 is out of range") {
+		t.Fatalf("bad: %s", err)
+	}
+}
@@ -0,0 +1,252 @@
+package mapstructure
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// keyNode is an intermediate node of the input built from nested keys
+// such as "filter.status" or "items[0]". Segments are kept as strings
+// until the target type is known: a node decoded into a slice or array
+// uses its numeric segments as indexes, while one decoded into a map or
+// struct uses them as keys.
+type keyNode struct {
+	entries map[string]interface{}
+
+	// maxLen bounds the length of the slice a node becomes, so that a
+	// sparse index can't allocate more than the size of the input
+	// warrants.
+	maxLen int
+}
+
+// DecodeForm decodes url.Values, such as a parsed query string or the
+// Form field of an http.Request, into output.
+//
+// Keys may use dots or brackets for nesting, e.g. "filter.status" or
+// "items[0][name]". Numeric segments index into slice fields and are
+// keys for map fields. Single values are unwrapped and parsed for scalar
+// fields, while slice fields receive every value given for a key.
+func DecodeForm(values url.Values, output interface{}) error {
+	val := reflect.ValueOf(output)
+	if val.Kind() != reflect.Ptr {
+		return errors.New("result must be a pointer")
+	}
+
+	if !val.Elem().CanAddr() {
+		return errors.New("result must be addressable (a pointer)")
+	}
+
+	return (&Decoder{Result: output}).DecodeForm(values)
+}
+
+// DecodeForm decodes the given url.Values to the target pointer specified
+// by the configuration.
+func (d *Decoder) DecodeForm(values url.Values) error {
+	input, err := buildFormInput(values)
+	if err != nil {
+		return err
+	}
+
+	return d.Decode(input)
+}
+
+func buildFormInput(values url.Values) (keyNode, error) {
+	keys := make([]string, 0, len(values))
+	maxLen := 0
+	for key, v := range values {
+		keys = append(keys, key)
+		maxLen += len(v)
+	}
+	sort.Strings(keys)
+
+	root := keyNode{entries: make(map[string]interface{}), maxLen: maxLen}
+	errors := make([]string, 0)
+	for _, key := range keys {
+		segments, err := parseFormKey(key)
+		if err != nil {
+			errors = appendErrors(errors, err)
+			continue
+		}
+
+		if err := setFormValue(root, key, segments, values[key]); err != nil {
+			errors = appendErrors(errors, err)
+		}
+	}
+
+	if len(errors) > 0 {
+		return keyNode{}, &Error{Errors: errors}
+	}
+
+	return root, nil
+}
+
+// parseFormKey splits a form key such as "items[0].name" into its
+// segments. A trailing "[]" is accepted and ignored.
+func parseFormKey(key string) ([]string, error) {
+	key = strings.TrimSuffix(key, "[]")
+
+	var segments []string
+	var current strings.Builder
+	for i := 0; i < len(key); i++ {
+		switch c := key[i]; c {
+		case '.':
+			if current.Len() == 0 && (i == 0 || key[i-1] != ']') {
+				return nil, fmt.Errorf("form key '%s' has an empty segment", key)
+			}
+			if current.Len() > 0 {
+				segments = append(segments, current.String())
+				current.Reset()
+			}
+		case '[':
+			if current.Len() > 0 {
+				segments = append(segments, current.String())
+				current.Reset()
+			}
+
+			end := strings.IndexByte(key[i:], ']')
+			if end <= 1 {
+				return nil, fmt.Errorf("form key '%s' has a malformed bracket", key)
+			}
+			segments = append(segments, key[i+1:i+end])
+			i += end
+		case ']':
+			return nil, fmt.Errorf("form key '%s' has a malformed bracket", key)
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	if current.Len() > 0 {
+		segments = append(segments, current.String())
+	} else if len(key) == 0 || key[len(key)-1] == '.' {
+		return nil, fmt.Errorf("form key '%s' has an empty segment", key)
+	}
+
+	return segments, nil
+}
+
+func setFormValue(root keyNode, key string, segments []string, values []string) error {
+	node := root
+	for i, segment := range segments {
+		next := node.entries[segment]
+
+		if i == len(segments)-1 {
+			switch existing := next.(type) {
+			case nil:
+				node.entries[segment] = textValue{key: key, values: values}
+			case textValue:
+				// Both "tags" and "tags[]" were given, so merge them.
+				existing.values = append(existing.values, values...)
+				node.entries[segment] = existing
+			default:
+				return fmt.Errorf("form key '%s' conflicts with a nested key", key)
+			}
+			return nil
+		}
+
+		switch n := next.(type) {
+		case nil:
+			child := keyNode{entries: make(map[string]interface{}), maxLen: root.maxLen}
+			node.entries[segment] = child
+			node = child
+		case keyNode:
+			node = n
+		case textValue:
+			return fmt.Errorf("form key '%s' conflicts with key '%s'", key, n.key)
+		}
+	}
+
+	return nil
+}
+
+func isIndexSegment(segment string) bool {
+	for _, c := range segment {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (d *Decoder) decodeKeyNode(name string, n keyNode, val reflect.Value) error {
+	switch getKind(val) {
+	case reflect.Ptr:
+		return d.decodePtr(name, n, val)
+	case reflect.Interface:
+		return d.decodeBasic(name, n.finish(), val)
+	case reflect.Slice, reflect.Array:
+		s, err := n.slice(name)
+		if err != nil {
+			return err
+		}
+		return d.decode(name, s, val)
+	default:
+		return d.decode(name, n.entries, val)
+	}
+}
+
+// slice returns the entries of n by index, leaving gaps in sparse
+// indexes as nil.
+func (n keyNode) slice(name string) ([]interface{}, error) {
+	length := 0
+	for segment := range n.entries {
+		idx, err := strconv.Atoi(segment)
+		if err != nil || !isIndexSegment(segment) {
+			return nil, fmt.Errorf("'%s' expected an index, got '%s'", name, segment)
+		}
+		if idx >= n.maxLen {
+			return nil, fmt.Errorf(
+				"'%s' index %s is out of range, the input has %d value(s)",
+				name, segment, n.maxLen)
+		}
+		if idx >= length {
+			length = idx + 1
+		}
+	}
+
+	s := make([]interface{}, length)
+	for segment, v := range n.entries {
+		idx, _ := strconv.Atoi(segment)
+		s[idx] = v
+	}
+
+	return s, nil
+}
+
+// finish turns n and the nodes below it into plain values for targets
+// of unknown type: a slice if every segment is a valid index and a map
+// otherwise.
+func (n keyNode) finish() interface{} {
+	finish := func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case keyNode:
+			return v.finish()
+		case textValue:
+			if len(v.values) == 1 {
+				return v.values[0]
+			}
+			return v.values
+		default:
+			return v
+		}
+	}
+
+	if s, err := n.slice(""); err == nil && len(s) > 0 {
+		for i, v := range s {
+			s[i] = finish(v)
+		}
+		return s
+	}
+
+	m := make(map[string]interface{}, len(n.entries))
+	for k, v := range n.entries {
+		m[k] = finish(v)
+	}
+	return m
+}
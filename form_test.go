@@ -0,0 +1,189 @@
+package mapstructure
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type FormItem struct {
+	Name  string `json:"name"`
+	Count uint   `json:"count"`
+}
+
+type FormFilter struct {
+	Status string `json:"status"`
+	Active *bool  `json:"active"`
+}
+
+type FormQuery struct {
+	Page   int        `json:"page"`
+	Ratio  float64    `json:"ratio"`
+	Tags   []string   `json:"tags"`
+	IDs    []int      `json:"ids"`
+	Filter FormFilter `json:"filter"`
+	Items  []FormItem `json:"items"`
+	Extra  interface{}
+}
+
+func TestDecodeForm(t *testing.T) {
+	t.Parallel()
+
+	values, err := url.ParseQuery(
+		"page=2&ratio=0.5&tags=a&tags[]=b&ids=1&ids=2" +
+			"&filter.status=open&filter[active]=true" +
+			"&items[0].name=foo&items[0][count]=3&items[1].name=bar" +
+			"&extra=x")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var result FormQuery
+	if err := DecodeForm(values, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := FormQuery{
+		Page:  2,
+		Ratio: 0.5,
+		Tags:  []string{"a", "b"},
+		IDs:   []int{1, 2},
+		Filter: FormFilter{
+			Status: "open",
+			Active: boolPtr(true),
+		},
+		Items: []FormItem{
+			{Name: "foo", Count: 3},
+			{Name: "bar"},
+		},
+		Extra: "x",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecodeForm_Errors(t *testing.T) {
+	t.Parallel()
+
+	values := url.Values{
+		"page":            []string{"first"},
+		"ratio":           []string{"1", "2"},
+		"items[0][count]": []string{"-1"},
+	}
+
+	var result FormQuery
+	err := DecodeForm(values, &result)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	for _, key := range []string{"'page'", "'ratio'", "'items[0][count]'"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("error should mention %s: %s", key, err)
+		}
+	}
+}
+
+func TestDecodeForm_Conflicts(t *testing.T) {
+	t.Parallel()
+
+	cases := []url.Values{
+		{"filter": []string{"x"}, "filter.status": []string{"open"}},
+		{"items[0].name": []string{"x"}, "items.name": []string{"y"}},
+		{"items[0": []string{"x"}},
+		{"filter..status": []string{"x"}},
+	}
+
+	for _, values := range cases {
+		var result FormQuery
+		if err := DecodeForm(values, &result); err == nil {
+			t.Errorf("expected error for %v", values)
+		}
+	}
+}
+
+func TestDecodeForm_NumericKeys(t *testing.T) {
+	t.Parallel()
+
+	type Query struct {
+		Labels map[string]string `json:"labels"`
+		Counts map[int]int       `json:"counts"`
+		Items  []FormItem        `json:"items"`
+		Extra  interface{}       `json:"extra"`
+	}
+
+	values, err := url.ParseQuery(
+		"labels[2024]=x&labels[env]=prod&counts[7]=1" +
+			"&items[1].name=b&items[0].name=a" +
+			"&extra[0]=a&extra[1][k]=v")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var result Query
+	if err := DecodeForm(values, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := Query{
+		Labels: map[string]string{"2024": "x", "env": "prod"},
+		Counts: map[int]int{7: 1},
+		Items:  []FormItem{{Name: "a"}, {Name: "b"}},
+		Extra: []interface{}{
+			"a",
+			map[string]interface{}{"k": "v"},
+		},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecodeForm_LargeIndexes(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		query    string
+		expected string
+	}{
+		{
+			"items[99999999999999999999].name=x",
+			"'items' expected an index, got '99999999999999999999'",
+		},
+		{
+			"items[50000000].name=x&items[0].name=y",
+			"'items' index 50000000 is out of range, the input has 2 value(s)",
+		},
+	}
+
+	for _, tc := range cases {
+		values, err := url.ParseQuery(tc.query)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		var result FormQuery
+		err = DecodeForm(values, &result)
+		if err == nil {
+			t.Fatalf("%s: should error", tc.query)
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Fatalf("%s: bad: %s", tc.query, err)
+		}
+	}
+
+	// Targets of unknown type keep large indexes as keys.
+	var result map[string]interface{}
+	if err := DecodeForm(url.Values{"a[50000000]": {"x"}}, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"a": map[string]interface{}{"50000000": "x"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
@@ -0,0 +1,229 @@
+package mapstructure
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const headerTagName = `header`
+
+// DecodeHeader decodes an http.Header, or any other metadata map such as
+// gRPC metadata, into the struct pointed to by output.
+//
+// Fields are matched by their "header" tag, falling back to the field
+// name, using canonical MIME header keys so "x-request-id" and
+// "X-Request-Id" are the same key. Slice fields receive every value given
+// for a header, other fields require exactly one value. Embedded structs
+// are flattened into the parent.
+func DecodeHeader(header map[string][]string, output interface{}) error {
+	val := reflect.ValueOf(output)
+	if val.Kind() != reflect.Ptr {
+		return errors.New("result must be a pointer")
+	}
+
+	if !val.Elem().CanAddr() {
+		return errors.New("result must be addressable (a pointer)")
+	}
+
+	return (&Decoder{Result: output}).DecodeHeader(header)
+}
+
+// DecodeHeader decodes the given header map to the target pointer
+// specified by the configuration.
+func (d *Decoder) DecodeHeader(header map[string][]string) error {
+	val := reflect.Indirect(reflect.ValueOf(d.Result))
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("result must point to a struct, got '%s'", val.Kind())
+	}
+
+	canonical := make(map[string][]string, len(header))
+	for k, v := range header {
+		key := textproto.CanonicalMIMEHeaderKey(k)
+		canonical[key] = append(canonical[key], v...)
+	}
+
+	d.reset()
+	errors := d.decodeStructFromHeader(canonical, val, make([]string, 0))
+	if d.limitErr != nil || len(errors) > 0 {
+		return d.decodeErrors(&Error{Errors: errors})
+	}
+
+	return nil
+}
+
+func (d *Decoder) decodeStructFromHeader(header map[string][]string, val reflect.Value, errors []string) []string {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		fieldValue := val.Field(i)
+		if !fieldValue.CanSet() {
+			continue
+		}
+
+		tagValue := strings.SplitN(f.Tag.Get(headerTagName), ",", 2)[0]
+		if tagValue == "-" {
+			continue
+		}
+
+		if f.Anonymous && tagValue == "" {
+			switch {
+			case fieldValue.Kind() == reflect.Struct:
+				errors = d.decodeStructFromHeader(header, fieldValue, errors)
+				continue
+			case fieldValue.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct:
+				if fieldValue.IsNil() {
+					fieldValue.Set(reflect.New(f.Type.Elem()))
+				}
+				errors = d.decodeStructFromHeader(header, fieldValue.Elem(), errors)
+				continue
+			}
+		}
+
+		key := f.Name
+		if tagValue != "" {
+			key = tagValue
+		}
+		key = textproto.CanonicalMIMEHeaderKey(key)
+
+		values, ok := header[key]
+		if !ok {
+			continue
+		}
+
+		// Only fields with input are skipped by stopping.
+		if d.stopLoop() {
+			break
+		}
+
+		decode := d.decodeWithNullPolicy
+		if hasTagOption(f.Tag.Get(headerTagName), sensitiveOption) {
+			decode = d.decodeSensitive
+		}
+
+		if err := decode(key, textValue{key: key, values: values}, fieldValue, d.NullPolicy); err != nil {
+			errors = d.appendErrors(errors, err)
+		}
+	}
+
+	return errors
+}
+
+// EncodeHeader encodes the struct input into an http.Header. It is the
+// inverse of DecodeHeader: slice fields produce one value per element and
+// the "omitempty" tag option skips empty fields.
+func EncodeHeader(input interface{}) (http.Header, error) {
+	val := reflect.Indirect(reflect.ValueOf(input))
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("input must be a struct, got '%s'", val.Kind())
+	}
+
+	header := make(http.Header)
+	if err := encodeStructToHeader(header, val); err != nil {
+		return nil, err
+	}
+
+	return header, nil
+}
+
+func encodeStructToHeader(header http.Header, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		v := val.Field(i)
+		tagParts := strings.Split(f.Tag.Get(headerTagName), ",")
+		if tagParts[0] == "-" {
+			continue
+		}
+
+		if f.Anonymous && tagParts[0] == "" {
+			inner := reflect.Indirect(v)
+			if inner.Kind() == reflect.Struct {
+				if err := encodeStructToHeader(header, inner); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		omitempty := false
+		for _, tag := range tagParts[1:] {
+			if tag == "omitempty" {
+				omitempty = true
+				break
+			}
+		}
+		if omitempty && isEmptyValue(v) {
+			continue
+		}
+
+		key := f.Name
+		if tagParts[0] != "" {
+			key = tagParts[0]
+		}
+
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				continue
+			}
+			v = v.Elem()
+		}
+
+		if (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && v.Type().Elem().Kind() != reflect.Uint8 {
+			for j := 0; j < v.Len(); j++ {
+				s, err := formatHeaderValue(v.Index(j))
+				if err != nil {
+					return fmt.Errorf("'%s[%d]': %s", key, j, err)
+				}
+				header.Add(key, s)
+			}
+			continue
+		}
+
+		s, err := formatHeaderValue(v)
+		if err != nil {
+			return fmt.Errorf("'%s': %s", key, err)
+		}
+		header.Add(key, s)
+	}
+
+	return nil
+}
+
+func formatHeaderValue(v reflect.Value) (string, error) {
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			b, err := m.MarshalText()
+			return string(b), err
+		}
+	}
+
+	switch getKind(v) {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return "", nil
+		}
+		return formatHeaderValue(v.Elem())
+	default:
+		return "", fmt.Errorf("unsupported header value type: %s", v.Type())
+	}
+}
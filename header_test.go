@@ -0,0 +1,118 @@
+package mapstructure
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type HeaderTrace struct {
+	TraceID string `header:"X-Trace-Id"`
+}
+
+type HeaderRequest struct {
+	HeaderTrace
+	RequestID   string   `header:"X-Request-Id"`
+	Retries     int      `header:"X-Retries"`
+	Accept      []string `header:"Accept,omitempty"`
+	Debug       *bool    `header:"X-Debug"`
+	ContentType string
+	Ignored     string `header:"-"`
+}
+
+func TestDecodeHeader(t *testing.T) {
+	t.Parallel()
+
+	header := http.Header{
+		"X-Request-Id": []string{"abc"},
+		"X-Retries":    []string{"3"},
+		"X-Trace-Id":   []string{"trace"},
+		"X-Debug":      []string{"true"},
+		"Accept":       []string{"text/html", "application/json"},
+		"Contenttype":  []string{"text/plain"},
+		"Ignored":      []string{"nope"},
+	}
+
+	var result HeaderRequest
+	if err := DecodeHeader(header, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := HeaderRequest{
+		HeaderTrace: HeaderTrace{TraceID: "trace"},
+		RequestID:   "abc",
+		Retries:     3,
+		Accept:      []string{"text/html", "application/json"},
+		Debug:       boolPtr(true),
+		ContentType: "text/plain",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecodeHeader_Metadata(t *testing.T) {
+	t.Parallel()
+
+	// gRPC metadata keys are lower case.
+	md := map[string][]string{
+		"x-request-id": []string{"abc"},
+		"x-retries":    []string{"1", "2"},
+	}
+
+	var result HeaderRequest
+	err := DecodeHeader(md, &result)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if result.RequestID != "abc" {
+		t.Errorf("bad: %#v", result)
+	}
+
+	expected := "1 error(s) decoding:\n\n* 'X-Retries' expected a single value, got 2"
+	if err.Error() != expected {
+		t.Errorf("bad error: %s", err)
+	}
+
+	// The fields after X-Retries have no input, so nothing was skipped.
+	decoder := &Decoder{Result: &result, FailFast: true}
+	if err := decoder.DecodeHeader(md); err == nil || err.Error() != expected {
+		t.Errorf("bad error: %v", err)
+	}
+}
+
+func TestEncodeHeader(t *testing.T) {
+	t.Parallel()
+
+	input := HeaderRequest{
+		HeaderTrace: HeaderTrace{TraceID: "trace"},
+		RequestID:   "abc",
+		Retries:     3,
+		ContentType: "text/plain",
+		Ignored:     "nope",
+	}
+
+	header, err := EncodeHeader(input)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := http.Header{
+		"X-Trace-Id":   []string{"trace"},
+		"X-Request-Id": []string{"abc"},
+		"X-Retries":    []string{"3"},
+		"Contenttype":  []string{"text/plain"},
+	}
+	if !reflect.DeepEqual(header, expected) {
+		t.Fatalf("bad: %#v", header)
+	}
+
+	var result HeaderRequest
+	if err := DecodeHeader(header, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	input.Ignored = ""
+	if !reflect.DeepEqual(result, input) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
@@ -0,0 +1,324 @@
+package mapstructure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PatchOperation is a single operation of an RFC 6902 JSON Patch
+// document. A document can be decoded into a []PatchOperation with
+// encoding/json.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch applies the operations of an RFC 6902 JSON Patch, "add",
+// "remove", "replace", "move", "copy" and "test", to the value target
+// points to.
+//
+// JSON Pointer paths are resolved through struct fields by key like in
+// Decode, map keys and slice indexes. Operation values are decoded into
+// the type found at their path. The patch is atomic: it is applied to a
+// copy which is only stored into target if every operation succeeds.
+func ApplyJSONPatch(target interface{}, ops []PatchOperation) error {
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("target must be a non-nil pointer")
+	}
+
+	return (&Decoder{Result: target}).ApplyJSONPatch(ops)
+}
+
+// ApplyJSONPatch applies the operations of an RFC 6902 JSON Patch to the
+// target pointer specified by the configuration.
+func (d *Decoder) ApplyJSONPatch(ops []PatchOperation) error {
+	val := reflect.ValueOf(d.Result).Elem()
+	work := copyRoot(val.Addr())
+	d.reset()
+
+	for i, op := range ops {
+		if err := d.applyPatchOperation(work, op); err != nil {
+			errors := make([]string, 0)
+			for _, msg := range appendErrors(nil, err) {
+				errors = append(errors, fmt.Sprintf(
+					"operation %d (%s '%s'): %s", i, op.Op, op.Path, msg))
+			}
+			return &Error{Errors: errors}
+		}
+	}
+
+	storeRoot(val.Addr(), work)
+	return nil
+}
+
+func (d *Decoder) applyPatchOperation(root reflect.Value, op PatchOperation) error {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add":
+		return d.patchAdd(root, path, op.Value)
+	case "remove":
+		return d.patchRemove(root, path)
+	case "replace":
+		return d.patchReplace("", root, path, op.Value)
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return err
+		}
+
+		value, err := d.patchGet(root, from)
+		if err != nil {
+			return err
+		}
+
+		if op.Op == "move" {
+			if op.Path == op.From {
+				return nil
+			}
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				return fmt.Errorf("cannot move '%s' into itself", op.From)
+			}
+			if err := d.patchRemove(root, from); err != nil {
+				return err
+			}
+		}
+
+		return d.patchAdd(root, path, value)
+	case "test":
+		current, err := d.patchGet(root, path)
+		if err != nil {
+			return err
+		}
+
+		if !d.patchEqual(op.Path, current, op.Value) {
+			return fmt.Errorf("'%s' test failed, value is '%v'", op.Path, current)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown operation '%s'", op.Op)
+	}
+}
+
+// parseJSONPointer splits an RFC 6901 JSON Pointer into its unescaped
+// reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer '%s'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// patchContainer walks to the parent of path and calls fn with the parent,
+// dereferenced to the struct, map, slice or array, and the last token.
+func (d *Decoder) patchContainer(root reflect.Value, path []string, fn func(name string, container reflect.Value, token string) error) error {
+	parent, token := path[:len(path)-1], path[len(path)-1]
+	return d.walkPath("", root, parent, false, func(name string, val reflect.Value) error {
+		for val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return fmt.Errorf("'%s' is nil", name)
+			}
+			val = val.Elem()
+		}
+
+		if val.Kind() != reflect.Interface {
+			return fn(name, val, token)
+		}
+
+		if val.IsNil() {
+			return fmt.Errorf("'%s' is nil", name)
+		}
+
+		elem := reflect.New(val.Elem().Type()).Elem()
+		elem.Set(val.Elem())
+		if err := fn(name, elem, token); err != nil {
+			return err
+		}
+		val.Set(elem)
+		return nil
+	})
+}
+
+// patchDecode decodes value into a fresh value of the given type, so the
+// previous value is replaced rather than merged.
+func (d *Decoder) patchDecode(name string, typ reflect.Type, value interface{}) (reflect.Value, error) {
+	result := reflect.New(typ).Elem()
+	if err := d.decode(name, value, result); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return result, nil
+}
+
+func (d *Decoder) patchGet(root reflect.Value, path []string) (interface{}, error) {
+	val, err := d.lookupPath("", root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return deepCopy(val).Interface(), nil
+}
+
+// patchEqual reports whether value, decoded into the type of current, is
+// equal to current. Null only equals nil values, and a value that would
+// lose precision or has keys without a field is not equal either.
+func (d *Decoder) patchEqual(name string, current, value interface{}) bool {
+	if value == nil {
+		v := reflect.ValueOf(current)
+		switch v.Kind() {
+		case reflect.Invalid:
+			return true
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			return v.IsNil()
+		default:
+			return false
+		}
+	}
+
+	// A nil interface has no type to decode the value into.
+	if current == nil {
+		return false
+	}
+
+	// Decode with a copy, so the warnings are only checked here.
+	sub := *d
+	sub.Warnings, sub.OnWarning = nil, nil
+
+	expected := reflect.New(reflect.TypeOf(current)).Elem()
+	if err := sub.decode(name, value, expected); err != nil {
+		return false
+	}
+
+	for _, w := range sub.Warnings {
+		if w.Code == WarningLossyConversion || w.Code == WarningUnusedKey {
+			return false
+		}
+	}
+
+	return reflect.DeepEqual(current, expected.Interface())
+}
+
+func (d *Decoder) patchAdd(root reflect.Value, path []string, value interface{}) error {
+	if len(path) == 0 {
+		return d.patchReplace("", root, path, value)
+	}
+
+	return d.patchContainer(root, path, func(name string, container reflect.Value, token string) error {
+		switch container.Kind() {
+		case reflect.Map:
+			key, err := d.pathMapKey(name, container.Type(), token)
+			if err != nil {
+				return err
+			}
+
+			elem, err := d.patchDecode(fmt.Sprintf("%s[%s]", name, token), container.Type().Elem(), value)
+			if err != nil {
+				return err
+			}
+
+			if container.IsNil() {
+				container.Set(reflect.MakeMap(container.Type()))
+			}
+			container.SetMapIndex(key, elem)
+			return nil
+		case reflect.Slice:
+			i := container.Len()
+			if token != "-" {
+				var err error
+				if i, err = pathIndex(name, token, container.Len()+1); err != nil {
+					return err
+				}
+			}
+
+			elem, err := d.patchDecode(fmt.Sprintf("%s[%d]", name, i), container.Type().Elem(), value)
+			if err != nil {
+				return err
+			}
+
+			result := reflect.MakeSlice(container.Type(), 0, container.Len()+1)
+			result = reflect.AppendSlice(result, container.Slice(0, i))
+			result = reflect.Append(result, elem)
+			result = reflect.AppendSlice(result, container.Slice(i, container.Len()))
+			container.Set(result)
+			return nil
+		default:
+			return d.patchReplace(name, container, []string{token}, value)
+		}
+	})
+}
+
+func (d *Decoder) patchRemove(root reflect.Value, path []string) error {
+	if len(path) == 0 {
+		root.Set(reflect.Zero(root.Type()))
+		return nil
+	}
+
+	return d.patchContainer(root, path, func(name string, container reflect.Value, token string) error {
+		switch container.Kind() {
+		case reflect.Map:
+			key, err := d.pathMapKey(name, container.Type(), token)
+			if err != nil {
+				return err
+			}
+
+			if !container.MapIndex(key).IsValid() {
+				return fmt.Errorf("'%s[%s]' does not exist", name, token)
+			}
+			container.SetMapIndex(key, reflect.Value{})
+			return nil
+		case reflect.Slice:
+			i, err := pathIndex(name, token, container.Len())
+			if err != nil {
+				return err
+			}
+
+			result := reflect.MakeSlice(container.Type(), 0, container.Len()-1)
+			result = reflect.AppendSlice(result, container.Slice(0, i))
+			result = reflect.AppendSlice(result, container.Slice(i+1, container.Len()))
+			container.Set(result)
+			return nil
+		case reflect.Struct:
+			field, _, ok := d.lookupStructField(container, token)
+			if !ok {
+				return fmt.Errorf("'%s' has no field '%s'", name, token)
+			}
+
+			// Struct fields can't be removed, so reset them instead.
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		default:
+			return fmt.Errorf("cannot remove '%s' from '%s' of type '%s'", token, name, container.Type())
+		}
+	})
+}
+
+func (d *Decoder) patchReplace(name string, root reflect.Value, path []string, value interface{}) error {
+	return d.walkPath(name, root, path, false, func(name string, val reflect.Value) error {
+		result, err := d.patchDecode(name, val.Type(), value)
+		if err != nil {
+			return err
+		}
+
+		val.Set(result)
+		return nil
+	})
+}
@@ -0,0 +1,255 @@
+package mapstructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	t.Parallel()
+
+	target := PatchDocument{
+		Title:  "Goodbye!",
+		Server: PatchServer{Host: "s", Port: 2},
+		Tags:   []string{"a", "b", "c"},
+		Labels: map[string]string{"keep": "1", "drop": "2", "a/b": "3"},
+		Servers: map[string]PatchServer{
+			"main": {Host: "m", Port: 3},
+		},
+	}
+
+	ops := []PatchOperation{
+		{Op: "test", Path: "/title", Value: "Goodbye!"},
+		{Op: "replace", Path: "/title", Value: "Hello!"},
+		{Op: "add", Path: "/tags/1", Value: "x"},
+		{Op: "add", Path: "/tags/-", Value: "d"},
+		{Op: "remove", Path: "/tags/0"},
+		{Op: "remove", Path: "/labels/drop"},
+		{Op: "replace", Path: "/labels/a~1b", Value: "4"},
+		{Op: "add", Path: "/servers/backup", Value: map[string]interface{}{"host": "b", "port": 4}},
+		{Op: "replace", Path: "/servers/main/port", Value: 30},
+		{Op: "copy", From: "/server", Path: "/author"},
+		{Op: "move", From: "/labels/keep", Path: "/labels/kept"},
+		{Op: "test", Path: "/server/port", Value: 2},
+	}
+
+	if err := ApplyJSONPatch(&target, ops); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := PatchDocument{
+		Title:  "Hello!",
+		Author: &PatchServer{Host: "s", Port: 2},
+		Server: PatchServer{Host: "s", Port: 2},
+		Tags:   []string{"x", "b", "c", "d"},
+		Labels: map[string]string{"kept": "1", "a/b": "4"},
+		Servers: map[string]PatchServer{
+			"main":   {Host: "m", Port: 30},
+			"backup": {Host: "b", Port: 4},
+		},
+	}
+	if !reflect.DeepEqual(target, expected) {
+		t.Fatalf("bad: %#v", target)
+	}
+}
+
+func TestApplyJSONPatch_interface(t *testing.T) {
+	t.Parallel()
+
+	target := map[string]interface{}{
+		"nested": map[string]interface{}{
+			"list": []interface{}{1, 2},
+		},
+	}
+
+	ops := []PatchOperation{
+		{Op: "add", Path: "/nested/list/0", Value: 0},
+		{Op: "add", Path: "/nested/name", Value: "n"},
+		{Op: "move", From: "/nested", Path: "/moved"},
+	}
+
+	if err := ApplyJSONPatch(&target, ops); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"moved": map[string]interface{}{
+			"list": []interface{}{0, 1, 2},
+			"name": "n",
+		},
+	}
+	if !reflect.DeepEqual(target, expected) {
+		t.Fatalf("bad: %#v", target)
+	}
+}
+
+func TestApplyJSONPatch_testNil(t *testing.T) {
+	t.Parallel()
+
+	target := map[string]interface{}{"value": nil}
+
+	if err := ApplyJSONPatch(&target, []PatchOperation{{Op: "test", Path: "/value"}}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	err := ApplyJSONPatch(&target, []PatchOperation{{Op: "test", Path: "/value", Value: "x"}})
+	if err == nil || !strings.Contains(err.Error(), "'/value' test failed") {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestApplyJSONPatch_testStrict(t *testing.T) {
+	t.Parallel()
+
+	target := PatchDocument{
+		Server: PatchServer{Host: "s", Port: 80},
+	}
+
+	passing := []PatchOperation{
+		{Op: "test", Path: "/server/port", Value: 80.0},
+		{Op: "test", Path: "/server", Value: map[string]interface{}{"host": "s", "port": 80}},
+		{Op: "test", Path: "/author", Value: nil},
+	}
+	for _, op := range passing {
+		if err := ApplyJSONPatch(&target, []PatchOperation{op}); err != nil {
+			t.Fatalf("%s: err: %s", op.Path, err)
+		}
+	}
+
+	failing := []PatchOperation{
+		{Op: "test", Path: "/server/port", Value: 80.9},
+		{Op: "test", Path: "/title", Value: nil},
+		{Op: "test", Path: "/server", Value: map[string]interface{}{"host": "s", "port": 80, "admin": true}},
+	}
+	for _, op := range failing {
+		err := ApplyJSONPatch(&target, []PatchOperation{op})
+		if err == nil || !strings.Contains(err.Error(), "test failed") {
+			t.Fatalf("%s %v: bad: %v", op.Path, op.Value, err)
+		}
+	}
+
+	target.Server.Port = 0
+	op := PatchOperation{Op: "test", Path: "/server/port", Value: nil}
+	if err := ApplyJSONPatch(&target, []PatchOperation{op}); err == nil {
+		t.Fatal("null should not equal 0")
+	}
+}
+
+func TestApplyJSONPatch_cycle(t *testing.T) {
+	t.Parallel()
+
+	target := map[string]interface{}{"name": "a"}
+	target["self"] = target
+
+	ops := []PatchOperation{
+		{Op: "replace", Path: "/name", Value: "b"},
+		{Op: "test", Path: "/self/self/name", Value: "b"},
+	}
+	if err := ApplyJSONPatch(&target, ops); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	self := target["self"].(map[string]interface{})
+	if target["name"] != "b" || self["name"] != "b" {
+		t.Fatalf("bad: %v %v", target["name"], self["name"])
+	}
+
+	type Node struct {
+		Name string `json:"name"`
+		Next *Node  `json:"next"`
+	}
+
+	var node Node
+	node.Next = &node
+	if err := ApplyJSONPatch(&node, []PatchOperation{{Op: "replace", Path: "/name", Value: "c"}}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if node.Name != "c" || node.Next != &node {
+		t.Fatalf("bad: %#v", node)
+	}
+}
+
+func TestApplyJSONPatch_atomic(t *testing.T) {
+	t.Parallel()
+
+	target := PatchDocument{
+		Title: "Goodbye!",
+		Tags:  []string{"a"},
+	}
+
+	ops := []PatchOperation{
+		{Op: "replace", Path: "/title", Value: "Hello!"},
+		{Op: "add", Path: "/tags/-", Value: "b"},
+		{Op: "test", Path: "/title", Value: "Goodbye!"},
+	}
+
+	err := ApplyJSONPatch(&target, ops)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "operation 2 (test '/title')") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	expected := PatchDocument{
+		Title: "Goodbye!",
+		Tags:  []string{"a"},
+	}
+	if !reflect.DeepEqual(target, expected) {
+		t.Fatalf("bad: %#v", target)
+	}
+}
+
+func TestApplyJSONPatch_errors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		op       PatchOperation
+		expected string
+	}{
+		{
+			PatchOperation{Op: "remove", Path: "/labels/missing"},
+			"'labels[missing]' does not exist",
+		},
+		{
+			PatchOperation{Op: "replace", Path: "/tags/5", Value: "x"},
+			"'tags' index 5 is out of range, length is 1",
+		},
+		{
+			PatchOperation{Op: "add", Path: "/unknown", Value: "x"},
+			"'' has no field 'unknown'",
+		},
+		{
+			PatchOperation{Op: "add", Path: "/server/port", Value: "http"},
+			"'server.port' expected type 'int'",
+		},
+		{
+			PatchOperation{Op: "move", From: "/server", Path: "/server/host"},
+			"cannot move '/server' into itself",
+		},
+		{
+			PatchOperation{Op: "merge", Path: "/title"},
+			"unknown operation 'merge'",
+		},
+		{
+			PatchOperation{Op: "add", Path: "title", Value: "x"},
+			"invalid JSON pointer 'title'",
+		},
+	}
+
+	for _, tc := range cases {
+		target := PatchDocument{
+			Tags:   []string{"a"},
+			Labels: map[string]string{},
+		}
+
+		err := ApplyJSONPatch(&target, []PatchOperation{tc.op})
+		if err == nil {
+			t.Fatalf("%s %s: should error", tc.op.Op, tc.op.Path)
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Fatalf("%s %s: bad: %s", tc.op.Op, tc.op.Path, err)
+		}
+	}
+}
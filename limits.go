@@ -0,0 +1,186 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Limits bounds the size of the input a Decoder accepts, so that decoding
+// untrusted input can't exhaust memory or the stack. A zero field means
+// no limit.
+type Limits struct {
+	// MaxDepth is the deepest nesting of maps, slices, arrays and structs.
+	MaxDepth int
+
+	// MaxElements is the total number of map entries and slice and array
+	// elements in the whole input.
+	MaxElements int
+
+	// MaxLength is the number of entries or elements of a single map,
+	// slice or array.
+	MaxLength int
+
+	// MaxStringBytes is the length in bytes of a single string.
+	MaxStringBytes int
+}
+
+// LimitKind is the limit a LimitError exceeded.
+type LimitKind uint8
+
+const (
+	// DepthLimit means Limits.MaxDepth was exceeded.
+	DepthLimit LimitKind = iota + 1
+
+	// ElementsLimit means Limits.MaxElements was exceeded.
+	ElementsLimit
+
+	// LengthLimit means Limits.MaxLength was exceeded.
+	LengthLimit
+
+	// StringBytesLimit means Limits.MaxStringBytes was exceeded.
+	StringBytesLimit
+)
+
+func (k LimitKind) String() string {
+	switch k {
+	case DepthLimit:
+		return "depth"
+	case ElementsLimit:
+		return "elements"
+	case LengthLimit:
+		return "length"
+	case StringBytesLimit:
+		return "string bytes"
+	default:
+		return fmt.Sprintf("LimitKind(%d)", uint8(k))
+	}
+}
+
+// LimitError is returned by Decode when the input exceeds one of the
+// Limits of the Decoder. Decoding stops as soon as a limit is exceeded,
+// so no other errors are reported with it.
+type LimitError struct {
+	Path  string
+	Kind  LimitKind
+	Limit int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("'%s' exceeds the %s limit of %d", e.Path, e.Kind, e.Limit)
+}
+
+// limitExceeded records the first exceeded limit, which stops decoding.
+func (d *Decoder) limitExceeded(name string, kind LimitKind, limit int) error {
+	err := &LimitError{Path: name, Kind: kind, Limit: limit}
+	if d.limitErr == nil {
+		d.limitErr = err
+	}
+
+	return err
+}
+
+// enterLimits checks input against the limits before it is decoded. The
+// returned function must be called once input is done to leave its
+// nesting level.
+func (d *Decoder) enterLimits(name string, input interface{}) (func(), error) {
+	leave := func() {}
+
+	// Text values only count as elements where they become a list: a
+	// slice target decodes them as one, and walkLimits counts them for
+	// interface targets.
+	if tv, ok := input.(textValue); ok {
+		for _, value := range tv.values {
+			if d.Limits.MaxStringBytes > 0 && len(value) > d.Limits.MaxStringBytes {
+				return leave, d.limitExceeded(name, StringBytesLimit, d.Limits.MaxStringBytes)
+			}
+		}
+		return leave, nil
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(input))
+	switch val.Kind() {
+	case reflect.String:
+		if d.Limits.MaxStringBytes > 0 && val.Len() > d.Limits.MaxStringBytes {
+			return leave, d.limitExceeded(name, StringBytesLimit, d.Limits.MaxStringBytes)
+		}
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		d.depth++
+		leave = func() { d.depth-- }
+		if d.Limits.MaxDepth > 0 && d.depth > d.Limits.MaxDepth {
+			return leave, d.limitExceeded(name, DepthLimit, d.Limits.MaxDepth)
+		}
+
+		if val.Kind() != reflect.Struct {
+			return leave, d.countElements(name, val.Len())
+		}
+	}
+
+	return leave, nil
+}
+
+func (d *Decoder) countElements(name string, n int) error {
+	if d.Limits.MaxLength > 0 && n > d.Limits.MaxLength {
+		return d.limitExceeded(name, LengthLimit, d.Limits.MaxLength)
+	}
+
+	d.elements += n
+	if d.Limits.MaxElements > 0 && d.elements > d.Limits.MaxElements {
+		return d.limitExceeded(name, ElementsLimit, d.Limits.MaxElements)
+	}
+
+	return nil
+}
+
+// walkLimits checks the whole of input against the limits. It is used for
+// input stored into an interface as is, which is not walked otherwise.
+func (d *Decoder) walkLimits(name string, input interface{}) error {
+	return d.walkLimitsSeen(name, input, make(map[visitKey]struct{}))
+}
+
+// walkLimitsSeen walks input like walkLimits. Maps and slices reachable
+// through several paths are stored once, so seen makes them count once,
+// which also ends cycles.
+func (d *Decoder) walkLimitsSeen(name string, input interface{}, seen map[visitKey]struct{}) error {
+	val := reflect.ValueOf(input)
+	if key, ok := inputVisitKey(val); ok {
+		if _, ok := seen[key]; ok {
+			return nil
+		}
+		seen[key] = struct{}{}
+	}
+
+	leave, err := d.enterLimits(name, input)
+	defer leave()
+	if err != nil {
+		return err
+	}
+
+	if tv, ok := input.(textValue); ok && len(tv.values) > 1 {
+		return d.countElements(name, len(tv.values))
+	}
+
+	val = reflect.Indirect(val)
+	switch val.Kind() {
+	case reflect.Map:
+		for _, k := range val.MapKeys() {
+			fieldName := fmt.Sprintf("%s[%v]", name, k)
+			if err := d.walkLimitsSeen(fieldName, k.Interface(), seen); err != nil {
+				return err
+			}
+			if err := d.walkLimitsSeen(fieldName, val.MapIndex(k).Interface(), seen); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		for i := 0; i < val.Len(); i++ {
+			if err := d.walkLimitsSeen(fmt.Sprintf("%s[%d]", name, i), val.Index(i).Interface(), seen); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,232 @@
+package mapstructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type LimitsItem struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+type LimitsConfig struct {
+	Items  []LimitsItem            `json:"items"`
+	Labels map[string]string       `json:"labels"`
+	Extra  map[string]interface{}  `json:"extra"`
+	Nested map[string][]LimitsItem `json:"nested"`
+}
+
+func TestDecoder_Limits(t *testing.T) {
+	t.Parallel()
+
+	deep := map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"c": 1}}}
+
+	cases := []struct {
+		limits   Limits
+		input    map[string]interface{}
+		expected LimitError
+	}{
+		{
+			Limits{MaxStringBytes: 3},
+			map[string]interface{}{"labels": map[string]interface{}{"env": "prod"}},
+			LimitError{Path: "labels[env]", Kind: StringBytesLimit, Limit: 3},
+		},
+		{
+			Limits{MaxStringBytes: 3},
+			map[string]interface{}{"labels": map[string]interface{}{"region": "eu"}},
+			LimitError{Path: "labels[region]", Kind: StringBytesLimit, Limit: 3},
+		},
+		{
+			Limits{MaxLength: 2},
+			map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"tags": []interface{}{"a", "b", "c"}},
+			}},
+			LimitError{Path: "items[0].tags", Kind: LengthLimit, Limit: 2},
+		},
+		{
+			Limits{MaxElements: 3},
+			map[string]interface{}{"items": []interface{}{
+				map[string]interface{}{"tags": []interface{}{"a", "b"}},
+			}},
+			LimitError{Path: "items[0].tags", Kind: ElementsLimit, Limit: 3},
+		},
+		{
+			Limits{MaxDepth: 3},
+			map[string]interface{}{"extra": deep},
+			LimitError{Path: "extra[a][b]", Kind: DepthLimit, Limit: 3},
+		},
+		{
+			Limits{MaxDepth: 3},
+			map[string]interface{}{"nested": map[string]interface{}{
+				"a": []interface{}{map[string]interface{}{"name": "x"}},
+			}},
+			LimitError{Path: "nested[a][0]", Kind: DepthLimit, Limit: 3},
+		},
+	}
+
+	for i, tc := range cases {
+		var result LimitsConfig
+		decoder := &Decoder{Result: &result, Limits: tc.limits}
+		err := decoder.Decode(tc.input)
+		if err == nil {
+			t.Fatalf("%d: should error", i)
+		}
+
+		lerr, ok := err.(*LimitError)
+		if !ok {
+			t.Fatalf("%d: bad: %#v", i, err)
+		}
+		if !reflect.DeepEqual(*lerr, tc.expected) {
+			t.Fatalf("%d: bad: %#v", i, lerr)
+		}
+	}
+}
+
+func TestDecoder_LimitsWithinBounds(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "a", "tags": []interface{}{"x", "y"}},
+			map[string]interface{}{"name": "b"},
+		},
+		"labels": map[string]interface{}{"env": "dev"},
+		"extra":  map[string]interface{}{"a": []interface{}{1, 2}},
+	}
+
+	var result LimitsConfig
+	decoder := &Decoder{
+		Result: &result,
+		Limits: Limits{
+			MaxDepth:       4,
+			MaxElements:    14,
+			MaxLength:      4,
+			MaxStringBytes: 3,
+		},
+	}
+	if err := decoder.Decode(input); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := LimitsConfig{
+		Items: []LimitsItem{
+			{Name: "a", Tags: []string{"x", "y"}},
+			{Name: "b"},
+		},
+		Labels: map[string]string{"env": "dev"},
+		Extra:  map[string]interface{}{"a": []interface{}{1, 2}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	if err := decoder.Decode(input); err != nil {
+		t.Fatalf("limits should be counted per Decode: %s", err)
+	}
+}
+
+func TestDecoder_LimitsPointers(t *testing.T) {
+	t.Parallel()
+
+	type Inner struct {
+		B int `json:"b"`
+	}
+
+	type Config struct {
+		A     *Inner      `json:"a"`
+		Tags  *[]string   `json:"tags"`
+		Extra interface{} `json:"extra"`
+	}
+
+	input := map[string]interface{}{
+		"a":     map[string]interface{}{"b": 1},
+		"tags":  []interface{}{"x", "y"},
+		"extra": map[string]interface{}{"c": 2},
+	}
+
+	// The root map and its three values, then three more elements below.
+	limits := Limits{MaxDepth: 2, MaxElements: 7}
+
+	result := Config{Extra: map[string]interface{}{}}
+	decoder := &Decoder{Result: &result, Limits: limits}
+	if err := decoder.Decode(input); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	limits.MaxElements--
+	result = Config{Extra: map[string]interface{}{}}
+	decoder = &Decoder{Result: &result, Limits: limits}
+	if _, ok := decoder.Decode(input).(*LimitError); !ok {
+		t.Fatal("should exceed the elements limit")
+	}
+}
+
+func TestDecoder_LimitsCycle(t *testing.T) {
+	t.Parallel()
+
+	self := map[string]interface{}{"n": "a"}
+	self["s"] = self
+
+	var result interface{}
+	decoder := &Decoder{Result: &result, Limits: Limits{MaxStringBytes: 3}}
+	if err := decoder.Decode(self); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	self["n"] = "long"
+	err := decoder.Decode(self)
+	if lerr, ok := err.(*LimitError); !ok || lerr.Kind != StringBytesLimit {
+		t.Fatalf("bad: %#v", err)
+	}
+}
+
+func TestDecoder_LimitsText(t *testing.T) {
+	t.Parallel()
+
+	type Query struct {
+		Tags []string `json:"tags"`
+	}
+
+	// The form map has one entry and the tags three elements.
+	values := map[string][]string{"tags": {"a", "b", "c"}}
+	for _, max := range []int{4, 3} {
+		var result Query
+		decoder := &Decoder{Result: &result, Limits: Limits{MaxElements: max}}
+		err := decoder.DecodeForm(values)
+		if max == 4 && err != nil {
+			t.Fatalf("%d: err: %s", max, err)
+		}
+		if max == 3 && err == nil {
+			t.Fatalf("%d: should error", max)
+		}
+	}
+
+	var result HeaderRequest
+	decoder := &Decoder{Result: &result, Limits: Limits{MaxElements: 2}}
+	header := map[string][]string{"Accept": {"a", "b"}}
+	for i := 0; i < 2; i++ {
+		if err := decoder.DecodeHeader(header); err != nil {
+			t.Fatalf("%d: limits should be counted per DecodeHeader: %s", i, err)
+		}
+	}
+}
+
+func TestLimitError(t *testing.T) {
+	t.Parallel()
+
+	var result LimitsConfig
+	decoder := &Decoder{Result: &result, Limits: Limits{MaxLength: 2}}
+	err := decoder.Decode(map[string]interface{}{
+		"labels": map[string]interface{}{"a": "1", "b": "2", "c": "3"},
+		"items":  []interface{}{map[string]interface{}{"name": 1}},
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	if !strings.Contains(err.Error(), "'labels' exceeds the length limit of 2") {
+		t.Fatalf("bad: %s", err)
+	}
+}
@@ -0,0 +1,68 @@
+package mapstructure
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// decodeMapKey decodes the map key k into key. String keys, such as those
+// of maps decoded from JSON, are parsed for numeric, bool, time.Duration
+// and encoding.TextUnmarshaler key types, and other keys are formatted for
+// string key types, so maps can be converted both ways.
+func (d *Decoder) decodeMapKey(name string, k reflect.Value, key reflect.Value) error {
+	for k.Kind() == reflect.Interface && !k.IsNil() {
+		k = k.Elem()
+	}
+
+	if k.Kind() == reflect.String && (key.Kind() != reflect.String || isTextUnmarshaler(key)) {
+		return d.decode(name, textValue{key: name, values: []string{k.String()}}, key)
+	}
+
+	if key.Kind() == reflect.String && k.Kind() != reflect.String {
+		s, ok, err := formatMapKey(k)
+		if err != nil {
+			return fmt.Errorf("error formatting key '%s': %s", name, err)
+		}
+		if ok {
+			key.SetString(s)
+			return nil
+		}
+	}
+
+	return d.decode(name, k.Interface(), key)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+func isTextUnmarshaler(val reflect.Value) bool {
+	return reflect.PtrTo(val.Type()).Implements(textUnmarshalerType)
+}
+
+// formatMapKey formats a non-string map key as text, the inverse of the
+// parsing done by decodeMapKey. It reports false for keys it can't
+// format.
+func formatMapKey(k reflect.Value) (string, bool, error) {
+	if m, ok := k.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), true, err
+	}
+
+	if k.Type() == durationType {
+		return k.Interface().(fmt.Stringer).String(), true, nil
+	}
+
+	switch getKind(k) {
+	case reflect.Bool:
+		return strconv.FormatBool(k.Bool()), true, nil
+	case reflect.Int:
+		return strconv.FormatInt(k.Int(), 10), true, nil
+	case reflect.Uint:
+		return strconv.FormatUint(k.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(k.Float(), 'g', -1, k.Type().Bits()), true, nil
+	default:
+		return "", false, nil
+	}
+}
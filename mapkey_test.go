@@ -0,0 +1,153 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type KeyLevel int
+
+func (l *KeyLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+func (l KeyLevel) MarshalText() ([]byte, error) {
+	switch l {
+	case 1:
+		return []byte("low"), nil
+	case 2:
+		return []byte("high"), nil
+	default:
+		return nil, fmt.Errorf("unknown level %d", int(l))
+	}
+}
+
+type TypedKeys struct {
+	Ints     map[int]string           `json:"ints"`
+	Ports    map[uint16]bool          `json:"ports"`
+	Flags    map[bool]int             `json:"flags"`
+	Weights  map[float64]string       `json:"weights"`
+	Timeouts map[time.Duration]string `json:"timeouts"`
+	Levels   map[KeyLevel]string      `json:"levels"`
+}
+
+func TestDecode_mapKeys(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"ints":     map[string]interface{}{"1": "one", "-2": "minus two"},
+		"ports":    map[string]interface{}{"80": true, "443": false},
+		"flags":    map[string]interface{}{"true": 1},
+		"weights":  map[string]interface{}{"0.5": "half"},
+		"timeouts": map[string]interface{}{"1m30s": "slow"},
+		"levels":   map[string]interface{}{"low": "l", "high": "h"},
+	}
+
+	var result TypedKeys
+	if err := Decode(input, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := TypedKeys{
+		Ints:     map[int]string{1: "one", -2: "minus two"},
+		Ports:    map[uint16]bool{80: true, 443: false},
+		Flags:    map[bool]int{true: 1},
+		Weights:  map[float64]string{0.5: "half"},
+		Timeouts: map[time.Duration]string{90 * time.Second: "slow"},
+		Levels:   map[KeyLevel]string{1: "l", 2: "h"},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecode_mapKeysErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input    map[string]interface{}
+		expected string
+	}{
+		{
+			map[string]interface{}{"ints": map[string]interface{}{"abc": "x"}},
+			"cannot parse 'ints[abc]' as int",
+		},
+		{
+			map[string]interface{}{"ports": map[string]interface{}{"70000": true}},
+			"cannot parse 'ports[70000]' as uint",
+		},
+		{
+			map[string]interface{}{"timeouts": map[string]interface{}{"soon": "x"}},
+			"cannot parse 'timeouts[soon]' as duration",
+		},
+		{
+			map[string]interface{}{"levels": map[string]interface{}{"medium": "x"}},
+			"cannot parse 'levels[medium]' as mapstructure.KeyLevel: unknown level \"medium\"",
+		},
+	}
+
+	for _, tc := range cases {
+		var result TypedKeys
+		err := Decode(tc.input, &result)
+		if err == nil {
+			t.Fatalf("%#v: should error", tc.input)
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Fatalf("%#v: bad: %s", tc.input, err)
+		}
+	}
+}
+
+func TestDecode_mapKeysFormat(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input    interface{}
+		expected map[string]interface{}
+	}{
+		{map[int]string{1: "one"}, map[string]interface{}{"1": "one"}},
+		{map[uint16]bool{80: true}, map[string]interface{}{"80": true}},
+		{map[bool]int{false: 0}, map[string]interface{}{"false": 0}},
+		{map[float64]string{0.5: "half"}, map[string]interface{}{"0.5": "half"}},
+		{map[time.Duration]string{time.Second: "s"}, map[string]interface{}{"1s": "s"}},
+		{map[KeyLevel]string{2: "h"}, map[string]interface{}{"high": "h"}},
+	}
+
+	for _, tc := range cases {
+		var result map[string]interface{}
+		if err := Decode(tc.input, &result); err != nil {
+			t.Fatalf("%#v: err: %s", tc.input, err)
+		}
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Fatalf("%#v: bad: %#v", tc.input, result)
+		}
+
+		roundTrip := reflect.New(reflect.TypeOf(tc.input))
+		if err := Decode(result, roundTrip.Interface()); err != nil {
+			t.Fatalf("%#v: err: %s", tc.input, err)
+		}
+		if !reflect.DeepEqual(roundTrip.Elem().Interface(), tc.input) {
+			t.Fatalf("%#v: bad: %#v", tc.input, roundTrip.Elem().Interface())
+		}
+	}
+
+	var result map[string]string
+	err := Decode(map[KeyLevel]string{3: "x"}, &result)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "error formatting key '[3]': unknown level 3") {
+		t.Fatalf("bad: %s", err)
+	}
+}
@@ -24,6 +24,93 @@ type Decoder struct {
 	// Result is a pointer to the struct that will contain the decoded
 	// value.
 	Result interface{}
+
+	// NullPolicy controls what happens when the input for a value is nil.
+	// It can be overridden per field with the "null" tag, for example
+	// `null:"error"`. Defaults to NullIgnore.
+	NullPolicy NullPolicy
+
+	// NamingStrategy derives the map key of untagged struct fields from
+	// their Go field name, both when decoding into and encoding from a
+	// struct. If nil, the Go field name is used as is.
+	NamingStrategy NamingStrategy
+
+	// Warnings collects the issues found while decoding that did not make
+	// it fail, such as the use of deprecated keys or keys that match no
+	// field. Every call to Decode appends to it.
+	Warnings []Warning
+
+	// OnWarning, if set, is called with every warning as it is found, in
+	// addition to it being appended to Warnings.
+	OnWarning func(Warning)
+
+	// Redact replaces the value of fields with the "sensitive" tag option
+	// by Redacted when encoding a struct into a map. Sensitive values are
+	// always left out of errors and warnings.
+	Redact bool
+
+	// KeySeparator, if set, makes Decode unflatten the keys of a map input
+	// with Unflatten first, so {"db.host": "x"} is decoded the same as
+	// {"db": {"host": "x"}}. Numeric segments index into slice fields and
+	// are keys for map fields.
+	KeySeparator string
+
+	// Atomic leaves Result untouched if decoding fails. Otherwise the
+	// fields decoded before an error are still set, as all errors are
+	// only returned at the end.
+	Atomic bool
+
+	// MaxErrors stops decoding once that many errors were found, instead
+	// of walking the whole input. The returned *Error is then marked as
+	// Truncated if input was left undecoded. Zero means no limit.
+	MaxErrors int
+
+	// FailFast stops decoding at the first error, the same as setting
+	// MaxErrors to 1.
+	FailFast bool
+
+	// Limits bounds the size of the input, for decoding untrusted input.
+	// Decode returns a *LimitError as soon as one is exceeded.
+	Limits Limits
+
+	// PreserveCycles makes a pointer in the input that points back to a
+	// value it is contained in point back to the same value in the output.
+	// Otherwise a cycle in the input is returned as an error, as it would
+	// have to be decoded endlessly.
+	PreserveCycles bool
+
+	// PreserveAliasing decodes a pointer or map that appears more than
+	// once in the input only once, and makes every place it appears in
+	// share the same output. This also preserves cycles through pointers.
+	PreserveAliasing bool
+
+	// ComplexFormat is how complex numbers are represented when encoding
+	// a struct into a map. Defaults to ComplexNative.
+	ComplexFormat ComplexFormat
+
+	// redacting is non-zero while a sensitive value is being decoded.
+	redacting int
+
+	// patch is set while applying a merge patch.
+	patch bool
+
+	// errorCount is the number of errors found so far by Decode, and
+	// truncated whether decoding stopped at the error limit with input
+	// left to decode.
+	errorCount int
+	truncated  bool
+
+	// depth, elements and limitErr track the input against Limits.
+	depth    int
+	elements int
+	limitErr *LimitError
+
+	// visiting holds the input values being decoded, to detect cycles.
+	visiting map[visitKey]visit
+
+	// shared holds the outputs of input pointers and maps decoded with
+	// PreserveAliasing.
+	shared map[sharedKey]reflect.Value
 }
 
 // Decode takes an input structure and uses reflection to translate it to
@@ -44,24 +131,96 @@ func Decode(input interface{}, output interface{}) error {
 // Decode decodes the given raw interface to the target pointer specified
 // by the configuration.
 func (d *Decoder) Decode(input interface{}) error {
-	return d.decode("", input, reflect.ValueOf(d.Result).Elem())
+	if d.KeySeparator != "" {
+		var err error
+		if input, err = d.unflattenInput(input); err != nil {
+			return err
+		}
+	}
+
+	val := reflect.ValueOf(d.Result).Elem()
+	d.reset()
+	if !d.Atomic {
+		return d.decodeErrors(d.decode("", input, val))
+	}
+
+	// Decode into a copy so existing values are still merged with, and
+	// only store it once nothing failed.
+	work := copyRoot(val.Addr())
+	if err := d.decode("", input, work); err != nil {
+		return d.decodeErrors(err)
+	}
+
+	storeRoot(val.Addr(), work)
+	return nil
+}
+
+// mergesIntoElem reports whether decodeBasic decodes data into the value
+// the interface val holds. A merge patch replaces the current value,
+// unless both the value and the patch are objects which are merged.
+func (d *Decoder) mergesIntoElem(data interface{}, val reflect.Value) bool {
+	if !val.IsValid() || !val.Elem().IsValid() {
+		return false
+	}
+
+	return !d.patch || (val.Elem().Kind() == reflect.Map && reflect.Indirect(reflect.ValueOf(data)).Kind() == reflect.Map)
+}
+
+// patchesObject reports whether decodeBasic applies the object patch data
+// to an empty object stored into the interface val.
+func (d *Decoder) patchesObject(data interface{}, val reflect.Value) bool {
+	objectType := reflect.TypeOf(map[string]interface{}{})
+	return d.patch && reflect.Indirect(reflect.ValueOf(data)).Kind() == reflect.Map && objectType.AssignableTo(val.Type())
+}
+
+// redecodesInterface reports whether decodeBasic decodes data again into
+// a value of a concrete type rather than storing it into val as is.
+func (d *Decoder) redecodesInterface(data interface{}, val reflect.Value) bool {
+	return d.mergesIntoElem(data, val) || d.patchesObject(data, val)
+}
+
+// reset clears the state kept while decoding, which is counted per call
+// of Decode or another entry point.
+func (d *Decoder) reset() {
+	d.errorCount, d.depth, d.elements, d.limitErr = 0, 0, 0, nil
+	d.truncated, d.shared = false, nil
 }
 
 // Decodes an unknown data type into a specific reflection value.
 func (d *Decoder) decode(name string, input interface{}, outVal reflect.Value) error {
+	return d.decodeWithNullPolicy(name, input, outVal, d.NullPolicy)
+}
+
+func (d *Decoder) decodeWithNullPolicy(name string, input interface{}, outVal reflect.Value, policy NullPolicy) error {
 	var inputVal reflect.Value
 	if input != nil {
 		inputVal = reflect.ValueOf(input)
 
 		// We need to check here if input is a typed nil. Typed nils won't
-		// match the "input == nil" below so we check that here.
-		if inputVal.Kind() == reflect.Ptr && inputVal.IsNil() {
-			input = nil
+		// match the "input == nil" below so we check that here. Unless
+		// nils are ignored, nil maps and slices count as null as well.
+		switch inputVal.Kind() {
+		case reflect.Ptr:
+			if inputVal.IsNil() {
+				input = nil
+			}
+		case reflect.Map, reflect.Slice:
+			if policy != NullIgnore && inputVal.IsNil() {
+				input = nil
+			}
 		}
 	}
 
+	if nullable, ok := asNullable(input, outVal); ok {
+		return d.decodeNullable(name, input, nullable)
+	}
+
+	if scanner, ok := asScanner(input, outVal); ok {
+		return d.decodeScanner(name, input, scanner)
+	}
+
 	if input == nil {
-		return nil
+		return d.decodeNull(name, outVal, policy)
 	}
 
 	if !inputVal.IsValid() {
@@ -71,6 +230,38 @@ func (d *Decoder) decode(name string, input interface{}, outVal reflect.Value) e
 		return nil
 	}
 
+	if d.Limits != (Limits{}) {
+		switch kind := getKind(outVal); {
+		case kind == reflect.Ptr, kind == reflect.Interface && d.redecodesInterface(input, outVal):
+			// The input is decoded again into the value pointed to or
+			// held, which checks it against the limits.
+		case kind == reflect.Interface && !d.patch:
+			if err := d.walkLimits(name, input); err != nil {
+				return err
+			}
+		default:
+			leave, err := d.enterLimits(name, input)
+			defer leave()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if tv, ok := input.(textValue); ok {
+		return d.decodeTextValue(name, tv, outVal)
+	}
+
+	if n, ok := input.(keyNode); ok {
+		return d.decodeKeyNode(name, n, outVal)
+	}
+
+	leave, err := d.enterVisit(name, inputVal, outVal)
+	defer leave()
+	if err != nil {
+		return err
+	}
+
 	switch getKind(outVal) {
 	case reflect.Bool:
 		return d.decodeBool(name, input, outVal)
@@ -84,6 +275,8 @@ func (d *Decoder) decode(name string, input interface{}, outVal reflect.Value) e
 		return d.decodeUint(name, input, outVal)
 	case reflect.Float32:
 		return d.decodeFloat(name, input, outVal)
+	case reflect.Complex64:
+		return d.decodeComplex(name, input, outVal)
 	case reflect.Struct:
 		return d.decodeStruct(name, input, outVal)
 	case reflect.Map:
@@ -105,8 +298,31 @@ func (d *Decoder) decode(name string, input interface{}, outVal reflect.Value) e
 // This decodes a basic type (bool, int, string, etc.) and sets the
 // value to "data" of that type.
 func (d *Decoder) decodeBasic(name string, data interface{}, val reflect.Value) error {
-	if val.IsValid() && val.Elem().IsValid() {
-		return d.decode(name, data, val.Elem())
+	if d.mergesIntoElem(data, val) {
+		// The value inside the interface can't be set, so decode into
+		// a copy and store that instead.
+		elem := val.Elem()
+		copied := reflect.New(elem.Type()).Elem()
+		copied.Set(elem)
+		if err := d.decode(name, data, copied); err != nil {
+			return err
+		}
+
+		val.Set(copied)
+		return nil
+	}
+
+	if d.patchesObject(data, val) {
+		// Apply an object patch to an empty object so nulls are dropped.
+		objectType := reflect.TypeOf(map[string]interface{}{})
+		merged := reflect.New(objectType).Elem()
+		merged.Set(reflect.MakeMap(objectType))
+		if err := d.decode(name, data, merged); err != nil {
+			return err
+		}
+
+		val.Set(merged)
+		return nil
 	}
 
 	dataVal := reflect.ValueOf(data)
@@ -156,7 +372,11 @@ func (d *Decoder) decodeInt(name string, data interface{}, val reflect.Value) er
 	case dataKind == reflect.Uint:
 		val.SetInt(int64(dataVal.Uint()))
 	case dataKind == reflect.Float32:
-		val.SetInt(int64(dataVal.Float()))
+		f := dataVal.Float()
+		val.SetInt(int64(f))
+		if float64(val.Int()) != f {
+			d.warn(name, WarningLossyConversion, "%v converted to %d", f, val.Int())
+		}
 	case dataType.PkgPath() == "encoding/json" && dataType.Name() == "Number":
 		jn := data.(json.Number)
 		i, err := jn.Int64()
@@ -194,6 +414,9 @@ func (d *Decoder) decodeUint(name string, data interface{}, val reflect.Value) e
 				name, f)
 		}
 		val.SetUint(uint64(f))
+		if float64(val.Uint()) != f {
+			d.warn(name, WarningLossyConversion, "%v converted to %d", f, val.Uint())
+		}
 	default:
 		return fmt.Errorf(
 			"'%s' expected type '%s', got unconvertible type '%s'",
@@ -264,6 +487,14 @@ func (d *Decoder) decodeMap(name string, data interface{}, val reflect.Value) er
 	dataVal := reflect.Indirect(reflect.ValueOf(data))
 	switch dataVal.Kind() {
 	case reflect.Map:
+		if d.PreserveAliasing && !d.patch {
+			if shared, ok := d.sharedValue(data, valType); ok {
+				val.Set(shared)
+				return nil
+			}
+			d.shareValue(data, valMap)
+		}
+
 		return d.decodeMapFromMap(name, dataVal, val, valMap)
 
 	case reflect.Struct:
@@ -296,20 +527,33 @@ func (d *Decoder) decodeMapFromMap(name string, dataVal reflect.Value, val refle
 	}
 
 	for _, k := range dataVal.MapKeys() {
-		fieldName := fmt.Sprintf("%s[%s]", name, k)
+		if d.stopLoop() {
+			break
+		}
+
+		fieldName := fmt.Sprintf("%s[%v]", name, k)
 
 		// First decode the key into the proper type
 		currentKey := reflect.Indirect(reflect.New(valKeyType))
-		if err := d.decode(fieldName, k.Interface(), currentKey); err != nil {
-			errors = appendErrors(errors, err)
+		if err := d.decodeMapKey(fieldName, k, currentKey); err != nil {
+			errors = d.appendErrors(errors, err)
 			continue
 		}
 
-		// Next decode the data into the proper type
+		// Next decode the data into the proper type. A merge patch deletes
+		// keys set to nil and merges into the existing values.
 		v := dataVal.MapIndex(k).Interface()
+		if d.patch && v == nil {
+			valMap.SetMapIndex(currentKey, reflect.Value{})
+			continue
+		}
+
 		currentVal := reflect.Indirect(reflect.New(valElemType))
+		if existing := valMap.MapIndex(currentKey); d.patch && existing.IsValid() {
+			currentVal.Set(existing)
+		}
 		if err := d.decode(fieldName, v, currentVal); err != nil {
-			errors = appendErrors(errors, err)
+			errors = d.appendErrors(errors, err)
 			continue
 		}
 
@@ -321,7 +565,7 @@ func (d *Decoder) decodeMapFromMap(name string, dataVal reflect.Value, val refle
 
 	// If we had errors, return those
 	if len(errors) > 0 {
-		return &Error{errors}
+		return &Error{Errors: errors}
 	}
 
 	return nil
@@ -337,9 +581,20 @@ func (d *Decoder) decodeMapFromStruct(name string, dataVal reflect.Value, val re
 			continue
 		}
 
-		// Next get the actual value of this field and verify it is assignable
-		// to the map value.
-		v := dataVal.Field(i)
+		// Next get the actual value of this field.
+		v, ok, err := structFieldValue(f, dataVal.Field(i), valMap.Type().Elem())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		if getKind(v) == reflect.Complex64 && d.ComplexFormat != ComplexNative {
+			v = reflect.ValueOf(d.encodeComplex(v.Complex()))
+		}
+
+		// Verify the value is assignable to the map value.
 		if !v.Type().AssignableTo(valMap.Type().Elem()) {
 			return fmt.Errorf("cannot assign type '%s' to map value field of type '%s'", v.Type(), valMap.Type().Elem())
 		}
@@ -360,7 +615,7 @@ func (d *Decoder) decodeMapFromStruct(name string, dataVal reflect.Value, val re
 		}
 
 		// Determine the name of the key in the map
-		keyName := f.Name
+		keyName := d.fieldKey(f.Name)
 		if tagParts[0] != "" {
 			if tagParts[0] == "-" {
 				continue
@@ -368,9 +623,17 @@ func (d *Decoder) decodeMapFromStruct(name string, dataVal reflect.Value, val re
 			keyName = tagParts[0]
 		}
 
-		switch v.Kind() {
+		if d.Redact && hasTagOption(tagValue, sensitiveOption) {
+			valMap.SetMapIndex(reflect.ValueOf(keyName), redactedValue(valMap.Type().Elem()))
+			continue
+		}
+
+		switch {
+		// values such as time.Time are opaque, so keep them as they are
+		case v.Kind() == reflect.Struct && v.Type().Implements(textMarshalerType):
+			valMap.SetMapIndex(reflect.ValueOf(keyName), v)
 		// this is an embedded struct, so handle it differently
-		case reflect.Struct:
+		case v.Kind() == reflect.Struct:
 			x := reflect.New(v.Type())
 			x.Elem().Set(v)
 
@@ -397,6 +660,41 @@ func (d *Decoder) decodeMapFromStruct(name string, dataVal reflect.Value, val re
 	return nil
 }
 
+// structFieldValue returns the value to encode for the struct field f
+// with value v. Nullable fields are left out when absent, and types
+// implementing driver.Valuer, such as sql.NullString, are encoded as
+// their plain value. Null values are the zero value of elemType.
+func structFieldValue(f reflect.StructField, v reflect.Value, elemType reflect.Type) (reflect.Value, bool, error) {
+	if nullable, ok := nullableField(v); ok {
+		switch nullable.Presence() {
+		case Absent:
+			return reflect.Value{}, false, nil
+		case Null:
+			return reflect.Zero(elemType), true, nil
+		default:
+			v = reflect.ValueOf(nullable.NullableValue()).Elem()
+			if v.Kind() == reflect.Interface && !v.IsNil() {
+				v = v.Elem()
+			}
+			return v, true, nil
+		}
+	}
+
+	if valuer, ok := asValuer(v); ok {
+		value, err := valuer.Value()
+		if err != nil {
+			return reflect.Value{}, false, fmt.Errorf("error encoding '%s': %s", f.Name, err)
+		}
+
+		if value == nil {
+			return reflect.Zero(elemType), true, nil
+		}
+		return reflect.ValueOf(value), true, nil
+	}
+
+	return v, true, nil
+}
+
 func (d *Decoder) decodePtr(name string, data interface{}, val reflect.Value) error {
 	// If the input data is nil, then we want to just set the output
 	// pointer to be nil as well.
@@ -426,11 +724,29 @@ func (d *Decoder) decodePtr(name string, data interface{}, val reflect.Value) er
 	valType := val.Type()
 	valElemType := valType.Elem()
 	if val.CanSet() {
+		if d.PreserveCycles {
+			if ptr, ok := d.visitedPtr(data, valType); ok {
+				val.Set(ptr)
+				return nil
+			}
+		}
+
+		if d.PreserveAliasing {
+			if ptr, ok := d.sharedValue(data, valType); ok {
+				val.Set(ptr)
+				return nil
+			}
+		}
+
 		realVal := val
 		if realVal.IsNil() {
 			realVal = reflect.New(valElemType)
 		}
 
+		if d.PreserveAliasing {
+			d.shareValue(data, realVal)
+		}
+
 		if err := d.decode(name, data, reflect.Indirect(realVal)); err != nil {
 			return err
 		}
@@ -464,8 +780,9 @@ func (d *Decoder) decodeSlice(name string, data interface{}, val reflect.Value)
 	valElemType := valType.Elem()
 	sliceType := reflect.SliceOf(valElemType)
 
+	// A merge patch replaces slices instead of merging them by index.
 	valSlice := val
-	if valSlice.IsNil() {
+	if valSlice.IsNil() || d.patch {
 		// Check input type
 		if dataValKind != reflect.Array && dataValKind != reflect.Slice {
 			return fmt.Errorf(
@@ -475,6 +792,9 @@ func (d *Decoder) decodeSlice(name string, data interface{}, val reflect.Value)
 
 		// If the input value is empty, then don't allocate since non-nil != nil
 		if dataVal.Len() == 0 {
+			if d.patch {
+				val.Set(reflect.MakeSlice(sliceType, 0, 0))
+			}
 			return nil
 		}
 
@@ -486,6 +806,10 @@ func (d *Decoder) decodeSlice(name string, data interface{}, val reflect.Value)
 	errors := make([]string, 0)
 
 	for i := 0; i < dataVal.Len(); i++ {
+		if d.stopLoop() {
+			break
+		}
+
 		currentData := dataVal.Index(i).Interface()
 		for valSlice.Len() <= i {
 			valSlice = reflect.Append(valSlice, reflect.Zero(valElemType))
@@ -494,7 +818,7 @@ func (d *Decoder) decodeSlice(name string, data interface{}, val reflect.Value)
 
 		fieldName := fmt.Sprintf("%s[%d]", name, i)
 		if err := d.decode(fieldName, currentData, currentField); err != nil {
-			errors = appendErrors(errors, err)
+			errors = d.appendErrors(errors, err)
 		}
 	}
 
@@ -503,7 +827,7 @@ func (d *Decoder) decodeSlice(name string, data interface{}, val reflect.Value)
 
 	// If there were errors, we return those
 	if len(errors) > 0 {
-		return &Error{errors}
+		return &Error{Errors: errors}
 	}
 
 	return nil
@@ -518,7 +842,7 @@ func (d *Decoder) decodeArray(name string, data interface{}, val reflect.Value)
 
 	valArray := val
 
-	if valArray.Interface() == reflect.Zero(valArray.Type()).Interface() {
+	if d.patch || valArray.Interface() == reflect.Zero(valArray.Type()).Interface() {
 		// Check input type
 		if dataValKind != reflect.Array && dataValKind != reflect.Slice {
 
@@ -541,12 +865,16 @@ func (d *Decoder) decodeArray(name string, data interface{}, val reflect.Value)
 	errors := make([]string, 0)
 
 	for i := 0; i < dataVal.Len(); i++ {
+		if d.stopLoop() {
+			break
+		}
+
 		currentData := dataVal.Index(i).Interface()
 		currentField := valArray.Index(i)
 
 		fieldName := fmt.Sprintf("%s[%d]", name, i)
 		if err := d.decode(fieldName, currentData, currentField); err != nil {
-			errors = appendErrors(errors, err)
+			errors = d.appendErrors(errors, err)
 		}
 	}
 
@@ -555,7 +883,7 @@ func (d *Decoder) decodeArray(name string, data interface{}, val reflect.Value)
 
 	// If there were errors, we return those
 	if len(errors) > 0 {
-		return &Error{errors}
+		return &Error{Errors: errors}
 	}
 
 	return nil
@@ -575,21 +903,25 @@ func (d *Decoder) decodeStruct(name string, data interface{}, val reflect.Value)
 	case reflect.Map:
 		return d.decodeStructFromMap(name, dataVal, val)
 	case reflect.Struct:
-		// Not the most efficient way to do this but we can optimize later if
-		// we want to. To convert from struct to struct we go to map first
-		// as an intermediary.
-		m := make(map[string]interface{})
-		mval := reflect.Indirect(reflect.ValueOf(&m))
-		if err := d.decodeMapFromStruct(name, dataVal, mval, mval); err != nil {
-			return err
-		}
-
-		return d.decodeStructFromMap(name, mval, val)
+		return d.decodeStructFromStruct(name, dataVal, val)
 	default:
 		return fmt.Errorf("'%s' expected a map, got '%s'", name, dataVal.Kind())
 	}
 }
 
+// decodeStructViaMap converts from struct to struct by going through a
+// map as an intermediary. It is used when decodeStructFromStruct can't map
+// the fields directly.
+func (d *Decoder) decodeStructViaMap(name string, dataVal, val reflect.Value) error {
+	m := make(map[string]interface{})
+	mval := reflect.Indirect(reflect.ValueOf(&m))
+	if err := d.decodeMapFromStruct(name, dataVal, mval, mval); err != nil {
+		return err
+	}
+
+	return d.decodeStructFromMap(name, mval, val)
+}
+
 func (d *Decoder) decodeStructFromMap(name string, dataVal, val reflect.Value) error {
 	dataValType := dataVal.Type()
 	if kind := dataValType.Key().Kind(); kind != reflect.String && kind != reflect.Interface {
@@ -603,6 +935,7 @@ func (d *Decoder) decodeStructFromMap(name string, dataVal, val reflect.Value) e
 		dataValKeys[dataValKey] = struct{}{}
 	}
 
+	usedKeys := make(map[interface{}]struct{})
 	errors := make([]string, 0)
 
 	// Compile the list of all the fields that we're going to be decoding
@@ -623,9 +956,10 @@ func (d *Decoder) decodeStructFromMap(name string, dataVal, val reflect.Value) e
 	}
 
 	// for fieldType, field := range fields {
+	stopped := false
 	for _, f := range fields {
 		field, fieldValue := f.field, f.val
-		fieldName := field.Name
+		fieldName := d.fieldKey(field.Name)
 
 		tagValue := field.Tag.Get(tagName)
 		tagValue = strings.SplitN(tagValue, ",", 2)[0]
@@ -633,31 +967,23 @@ func (d *Decoder) decodeStructFromMap(name string, dataVal, val reflect.Value) e
 			fieldName = tagValue
 		}
 
-		rawMapKey := reflect.ValueOf(fieldName)
-		rawMapVal := dataVal.MapIndex(rawMapKey)
+		rawMapKey, rawMapVal, err := d.lookupField(name, fieldName, field, dataVal, dataValKeys)
+		if err != nil {
+			errors = d.appendErrors(errors, err)
+			continue
+		}
+
 		if !rawMapVal.IsValid() {
-			// Do a slower search by iterating over each key and
-			// doing case-insensitive search.
-			for dataValKey := range dataValKeys {
-				mK, ok := dataValKey.Interface().(string)
-				if !ok {
-					// Not a string key
-					continue
-				}
-
-				if strings.EqualFold(mK, fieldName) {
-					rawMapKey = dataValKey
-					rawMapVal = dataVal.MapIndex(dataValKey)
-					break
-				}
-			}
+			// There was no matching key in the map for the value in
+			// the struct. Just ignore.
+			continue
+		}
 
-			if !rawMapVal.IsValid() {
-				// There was no matching key in the map for the value in
-				// the struct. Just ignore.
-				continue
-			}
+		// Only fields with input are skipped by stopping.
+		if stopped = d.stopLoop(); stopped {
+			break
 		}
+		usedKeys[rawMapKey.Interface()] = struct{}{}
 
 		if !fieldValue.IsValid() {
 			// This should never happen
@@ -676,18 +1002,39 @@ func (d *Decoder) decodeStructFromMap(name string, dataVal, val reflect.Value) e
 			fieldName = fmt.Sprintf("%s.%s", name, fieldName)
 		}
 
-		if err := d.decode(fieldName, rawMapVal.Interface(), fieldValue); err != nil {
-			errors = appendErrors(errors, err)
+		if err := d.decodeField(fieldName, field, rawMapVal.Interface(), fieldValue); err != nil {
+			errors = d.appendErrors(errors, err)
 		}
 	}
 
+	// Keys after decoding stopped were never looked at.
+	if !stopped {
+		d.warnUnusedKeys(name, dataVal, usedKeys)
+	}
+
 	if len(errors) > 0 {
-		return &Error{errors}
+		return &Error{Errors: errors}
 	}
 
 	return nil
 }
 
+// decodeField decodes input into the value of the struct field, applying
+// the null policy and sensitive option of the field.
+func (d *Decoder) decodeField(fieldName string, field reflect.StructField, input interface{}, fieldValue reflect.Value) error {
+	policy, err := fieldNullPolicy(fieldName, field, d.NullPolicy)
+	if err != nil {
+		return err
+	}
+
+	decode := d.decodeWithNullPolicy
+	if hasTagOption(field.Tag.Get(tagName), sensitiveOption) {
+		decode = d.decodeSensitive
+	}
+
+	return decode(fieldName, input, fieldValue, policy)
+}
+
 func isEmptyValue(v reflect.Value) bool {
 	switch getKind(v) {
 	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
@@ -717,6 +1064,8 @@ func getKind(val reflect.Value) reflect.Kind {
 		return reflect.Uint
 	case kind >= reflect.Float32 && kind <= reflect.Float64:
 		return reflect.Float32
+	case kind == reflect.Complex64 || kind == reflect.Complex128:
+		return reflect.Complex64
 	default:
 		return kind
 	}
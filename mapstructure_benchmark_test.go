@@ -2,6 +2,7 @@ package mapstructure
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -210,3 +211,53 @@ func Benchmark_DecodeTagged(b *testing.B) {
 		Decode(input, &result)
 	}
 }
+
+type benchmarkAddress struct {
+	Street string
+	City   string
+}
+
+type benchmarkSource struct {
+	Name    string
+	Age     int
+	Emails  []string
+	Address benchmarkAddress
+}
+
+type benchmarkTarget struct {
+	Name    string
+	Age     int64
+	Emails  []string
+	Address benchmarkAddress
+}
+
+func Benchmark_DecodeStruct(b *testing.B) {
+	input := benchmarkSource{
+		Name:    "Benchmark",
+		Age:     91,
+		Emails:  []string{"one", "two", "three"},
+		Address: benchmarkAddress{Street: "Main", City: "Town"},
+	}
+
+	var result benchmarkTarget
+	for i := 0; i < b.N; i++ {
+		Decode(input, &result)
+	}
+}
+
+func Benchmark_DecodeStructViaMap(b *testing.B) {
+	input := benchmarkSource{
+		Name:    "Benchmark",
+		Age:     91,
+		Emails:  []string{"one", "two", "three"},
+		Address: benchmarkAddress{Street: "Main", City: "Town"},
+	}
+
+	var result benchmarkTarget
+	decoder := &Decoder{Result: &result}
+	dataVal := reflect.ValueOf(input)
+	val := reflect.ValueOf(&result).Elem()
+	for i := 0; i < b.N; i++ {
+		decoder.decodeStructViaMap("", dataVal, val)
+	}
+}
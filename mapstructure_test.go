@@ -3,6 +3,7 @@ package mapstructure
 import (
 	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -1438,3 +1439,160 @@ func uintPtr(v uint) *uint                    { return &v }
 func boolPtr(v bool) *bool                    { return &v }
 func floatPtr(v float64) *float64             { return &v }
 func interfacePtr(v interface{}) *interface{} { return &v }
+
+func TestDecoder_Atomic(t *testing.T) {
+	t.Parallel()
+
+	type Server struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+
+	type Config struct {
+		Name    string            `json:"name"`
+		Server  *Server           `json:"server"`
+		Tags    []string          `json:"tags"`
+		Labels  map[string]string `json:"labels"`
+		Retries int               `json:"retries"`
+	}
+
+	initial := func() Config {
+		return Config{
+			Name:   "old",
+			Server: &Server{Host: "a", Port: 1},
+			Tags:   []string{"x"},
+			Labels: map[string]string{"env": "dev"},
+		}
+	}
+
+	result := initial()
+	server := result.Server
+	decoder := &Decoder{Result: &result, Atomic: true}
+	err := decoder.Decode(map[string]interface{}{
+		"name":    "new",
+		"server":  map[string]interface{}{"port": 2},
+		"tags":    []interface{}{"y"},
+		"labels":  map[string]interface{}{"env": "prod"},
+		"retries": "many",
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	if !reflect.DeepEqual(result, initial()) {
+		t.Fatalf("bad: %#v", result)
+	}
+	if result.Server != server || server.Port != 1 {
+		t.Fatalf("bad: %#v", server)
+	}
+
+	err = decoder.Decode(map[string]interface{}{
+		"server": map[string]interface{}{"port": 2},
+		"labels": map[string]interface{}{"region": "eu"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := initial()
+	expected.Server.Port = 2
+	expected.Labels["region"] = "eu"
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecoder_AtomicCycles(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name string `json:"name"`
+		Next *Node  `json:"next"`
+	}
+
+	type Config struct {
+		Head  *Node `json:"head"`
+		Alias *Node `json:"alias"`
+	}
+
+	head := &Node{Name: "a"}
+	head.Next = head
+	result := Config{Head: head, Alias: head}
+
+	decoder := &Decoder{Result: &result, Atomic: true}
+	err := decoder.Decode(map[string]interface{}{
+		"head": map[string]interface{}{"name": "b"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.Head.Name != "b" || result.Head.Next != result.Head || result.Alias != result.Head {
+		t.Fatalf("bad: %#v", result)
+	}
+	if head.Name != "a" {
+		t.Fatalf("original should be unchanged: %#v", head)
+	}
+}
+
+func TestDecoder_MaxErrors(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Count int `json:"count"`
+	}
+
+	type Config struct {
+		Items []Item `json:"items"`
+		Name  string `json:"name"`
+	}
+
+	input := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"count": "a"},
+			map[string]interface{}{"count": "b"},
+			map[string]interface{}{"count": "c"},
+		},
+		"name": 1,
+	}
+
+	var result Config
+	decoder := &Decoder{Result: &result, MaxErrors: 2}
+	err := decoder.Decode(input)
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	derr := err.(*Error)
+	if !derr.Truncated || len(derr.Errors) != 2 {
+		t.Fatalf("bad: %#v", derr)
+	}
+	if !strings.Contains(err.Error(), "2 error(s) decoding, stopped early") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	decoder = &Decoder{Result: &result, FailFast: true}
+	err = decoder.Decode(input)
+	if err == nil {
+		t.Fatal("should error")
+	}
+
+	derr = err.(*Error)
+	if !derr.Truncated || len(derr.Errors) != 1 {
+		t.Fatalf("bad: %#v", derr)
+	}
+
+	// Reaching the limit with nothing left to decode is not truncated.
+	for _, max := range []int{4, 10} {
+		decoder = &Decoder{Result: &result, MaxErrors: max}
+		err = decoder.Decode(input)
+		if err == nil {
+			t.Fatal("should error")
+		}
+
+		derr = err.(*Error)
+		if derr.Truncated || len(derr.Errors) != 4 {
+			t.Fatalf("%d: bad: %#v", max, derr)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package mapstructure
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingStrategy derives the key of an untagged struct field from its Go
+// field name. SnakeCase, KebabCase, CamelCase and ScreamingSnakeCase are
+// provided, but any function can be used.
+type NamingStrategy func(fieldName string) string
+
+// SnakeCase converts a field name such as "HTTPPort" to "http_port".
+func SnakeCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "_"))
+}
+
+// KebabCase converts a field name such as "HTTPPort" to "http-port".
+func KebabCase(fieldName string) string {
+	return strings.ToLower(strings.Join(splitWords(fieldName), "-"))
+}
+
+// ScreamingSnakeCase converts a field name such as "HTTPPort" to
+// "HTTP_PORT".
+func ScreamingSnakeCase(fieldName string) string {
+	return strings.ToUpper(strings.Join(splitWords(fieldName), "_"))
+}
+
+// CamelCase converts a field name such as "HTTPPort" to "httpPort".
+func CamelCase(fieldName string) string {
+	words := splitWords(fieldName)
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			word = string(runes)
+		}
+		words[i] = word
+	}
+
+	return strings.Join(words, "")
+}
+
+// splitWords splits a Go identifier into its words, keeping acronyms such
+// as "HTTP" in "HTTPPort" together and digits with the preceding word.
+// Underscores separate words and are dropped.
+func splitWords(s string) []string {
+	runes := []rune(s)
+
+	var words []string
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+
+		prev := runes[i-1]
+		switch {
+		case unicode.IsLower(prev), unicode.IsDigit(prev):
+			// "maxRetry" or "ipv4Addr"
+		case unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// The end of an acronym, "HTTPPort"
+		default:
+			continue
+		}
+
+		words = append(words, string(runes[start:i]))
+		start = i
+	}
+
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// fieldKey returns the map key for a struct field without a tag name.
+func (d *Decoder) fieldKey(fieldName string) string {
+	if d.NamingStrategy == nil {
+		return fieldName
+	}
+
+	return d.NamingStrategy(fieldName)
+}
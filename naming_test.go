@@ -0,0 +1,109 @@
+package mapstructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNamingStrategies(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		input     string
+		snake     string
+		kebab     string
+		camel     string
+		screaming string
+	}{
+		{"MaxRetryCount", "max_retry_count", "max-retry-count", "maxRetryCount", "MAX_RETRY_COUNT"},
+		{"HTTPPort", "http_port", "http-port", "httpPort", "HTTP_PORT"},
+		{"UserID", "user_id", "user-id", "userId", "USER_ID"},
+		{"ID", "id", "id", "id", "ID"},
+		{"TLS13Enabled", "tls13_enabled", "tls13-enabled", "tls13Enabled", "TLS13_ENABLED"},
+		{"Name", "name", "name", "name", "NAME"},
+		{"Already_Snake", "already_snake", "already-snake", "alreadySnake", "ALREADY_SNAKE"},
+	}
+
+	for _, tc := range cases {
+		if got := SnakeCase(tc.input); got != tc.snake {
+			t.Errorf("SnakeCase(%q) = %q", tc.input, got)
+		}
+		if got := KebabCase(tc.input); got != tc.kebab {
+			t.Errorf("KebabCase(%q) = %q", tc.input, got)
+		}
+		if got := CamelCase(tc.input); got != tc.camel {
+			t.Errorf("CamelCase(%q) = %q", tc.input, got)
+		}
+		if got := ScreamingSnakeCase(tc.input); got != tc.screaming {
+			t.Errorf("ScreamingSnakeCase(%q) = %q", tc.input, got)
+		}
+	}
+}
+
+type NamedConfig struct {
+	MaxRetryCount int
+	HTTPPort      int
+	Name          string `json:"display_name"`
+}
+
+func TestDecode_NamingStrategy(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"max_retry_count": 3,
+		"HTTP_PORT":       8080,
+		"display_name":    "foo",
+	}
+
+	var result NamedConfig
+	d := &Decoder{Result: &result, NamingStrategy: SnakeCase}
+	if err := d.Decode(input); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := NamedConfig{MaxRetryCount: 3, HTTPPort: 8080, Name: "foo"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecode_NamingStrategyEncode(t *testing.T) {
+	t.Parallel()
+
+	input := NamedConfig{MaxRetryCount: 3, HTTPPort: 8080, Name: "foo"}
+
+	var result map[string]interface{}
+	d := &Decoder{Result: &result, NamingStrategy: KebabCase}
+	if err := d.Decode(input); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"max-retry-count": 3,
+		"http-port":       8080,
+		"display_name":    "foo",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecode_NamingStrategyCustom(t *testing.T) {
+	t.Parallel()
+
+	var result NamedConfig
+	d := &Decoder{
+		Result: &result,
+		NamingStrategy: func(fieldName string) string {
+			return "x-" + strings.ToLower(fieldName)
+		},
+	}
+	if err := d.Decode(map[string]interface{}{"x-httpport": 1, "httpport": 2}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.HTTPPort != 1 {
+		t.Fatalf("bad: %#v", result)
+	}
+}
@@ -0,0 +1,66 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const nullTagName = `null`
+
+// NullPolicy controls how a nil input value is decoded.
+type NullPolicy uint8
+
+const (
+	// NullIgnore leaves the target untouched when the input is nil. This
+	// is the default.
+	NullIgnore NullPolicy = iota
+
+	// NullZero resets the target to its zero value when the input is nil
+	// or a nil map or slice.
+	NullZero
+
+	// NullError fails with a "must not be null" error when the input is
+	// nil or a nil map or slice.
+	NullError
+)
+
+func (p NullPolicy) String() string {
+	switch p {
+	case NullIgnore:
+		return "ignore"
+	case NullZero:
+		return "zero"
+	case NullError:
+		return "error"
+	default:
+		return fmt.Sprintf("NullPolicy(%d)", uint8(p))
+	}
+}
+
+// fieldNullPolicy returns the policy set by the "null" tag of the field,
+// or def if the field has no such tag.
+func fieldNullPolicy(name string, field reflect.StructField, def NullPolicy) (NullPolicy, error) {
+	tagValue, ok := field.Tag.Lookup(nullTagName)
+	if !ok {
+		return def, nil
+	}
+
+	for _, p := range []NullPolicy{NullIgnore, NullZero, NullError} {
+		if tagValue == p.String() {
+			return p, nil
+		}
+	}
+
+	return def, fmt.Errorf("'%s' has an invalid null policy '%s'", name, tagValue)
+}
+
+func (d *Decoder) decodeNull(name string, val reflect.Value, policy NullPolicy) error {
+	switch policy {
+	case NullZero:
+		val.Set(reflect.Zero(val.Type()))
+	case NullError:
+		return fmt.Errorf("'%s' must not be null", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package mapstructure
+
+import (
+	"reflect"
+	"testing"
+)
+
+type NullPolicies struct {
+	Name    string
+	Tags    []string
+	Extra   map[string]string
+	Nested  *Basic
+	Zeroed  int    `null:"zero"`
+	Strict  string `null:"error"`
+	Ignored int    `null:"ignore"`
+}
+
+func TestDecode_NullPolicy(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"name":    nil,
+		"tags":    []string(nil),
+		"extra":   nil,
+		"nested":  (*Basic)(nil),
+		"zeroed":  nil,
+		"ignored": nil,
+	}
+
+	initial := NullPolicies{
+		Name:    "name",
+		Tags:    []string{"a"},
+		Extra:   map[string]string{"a": "b"},
+		Nested:  &Basic{VString: "foo"},
+		Zeroed:  1,
+		Ignored: 2,
+	}
+
+	cases := []struct {
+		policy   NullPolicy
+		expected NullPolicies
+	}{
+		{
+			NullIgnore,
+			NullPolicies{
+				Name:    "name",
+				Tags:    []string{"a"},
+				Extra:   map[string]string{"a": "b"},
+				Nested:  &Basic{VString: "foo"},
+				Ignored: 2,
+			},
+		},
+		{
+			NullZero,
+			NullPolicies{Ignored: 2},
+		},
+	}
+
+	for _, tc := range cases {
+		result := initial
+		result.Tags = append([]string(nil), initial.Tags...)
+		result.Extra = map[string]string{"a": "b"}
+		d := &Decoder{Result: &result, NullPolicy: tc.policy}
+		if err := d.Decode(input); err != nil {
+			t.Fatalf("%s: err: %s", tc.policy, err)
+		}
+
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("%s: bad: %#v", tc.policy, result)
+		}
+	}
+}
+
+func TestDecode_NullPolicyError(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"name":   nil,
+		"tags":   []interface{}{"a", nil},
+		"strict": nil,
+		"zeroed": nil,
+	}
+
+	var result NullPolicies
+	d := &Decoder{Result: &result, NullPolicy: NullError}
+	err := d.Decode(input)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	expected := "3 error(s) decoding:\n\n" +
+		"* 'Name' must not be null\n" +
+		"* 'Strict' must not be null\n" +
+		"* 'Tags[1]' must not be null"
+	if err.Error() != expected {
+		t.Fatalf("bad error: %s", err)
+	}
+
+	// The tag overrides the decoder policy in both directions.
+	result = NullPolicies{}
+	err = Decode(map[string]interface{}{"strict": nil}, &result)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestDecode_NullPolicyInvalidTag(t *testing.T) {
+	t.Parallel()
+
+	var result struct {
+		Name string `null:"maybe"`
+	}
+	if err := Decode(map[string]interface{}{"name": "x"}, &result); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,118 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Presence records whether the key of a Nullable field was absent from
+// the input, explicitly null or given a value.
+type Presence uint8
+
+const (
+	// Absent means the key was not in the input. It is the zero value so
+	// fields the decoder never touches report it.
+	Absent Presence = iota
+
+	// Null means the key was in the input with a nil value.
+	Null
+
+	// Present means the key was in the input with a non-nil value.
+	Present
+)
+
+func (p Presence) String() string {
+	switch p {
+	case Absent:
+		return "absent"
+	case Null:
+		return "null"
+	case Present:
+		return "present"
+	default:
+		return fmt.Sprintf("Presence(%d)", uint8(p))
+	}
+}
+
+// Nullable is implemented by field types that need to tell an omitted
+// key from one that is explicitly null, such as the fields of a PATCH
+// request. It must be implemented on the pointer to the field type.
+//
+// When decoding, a nil input resets the value to its zero value and
+// records Null, while any other input is decoded into NullableValue and
+// records Present. When encoding to a map, Absent fields are omitted,
+// Null fields produce nil and Present fields produce their value.
+type Nullable interface {
+	// NullableValue returns a pointer to the value non-nil input is
+	// decoded into.
+	NullableValue() interface{}
+
+	// SetPresence records the state of the field.
+	SetPresence(Presence)
+
+	// Presence returns the recorded state of the field.
+	Presence() Presence
+}
+
+// Optional is a Nullable that holds any value, decoded the same way as
+// into an interface{}. Types that need a typed value can implement
+// Nullable the same way.
+type Optional struct {
+	State Presence
+	Value interface{}
+}
+
+// NullableValue implements Nullable.
+func (o *Optional) NullableValue() interface{} { return &o.Value }
+
+// SetPresence implements Nullable.
+func (o *Optional) SetPresence(p Presence) { o.State = p }
+
+// Presence implements Nullable.
+func (o *Optional) Presence() Presence { return o.State }
+
+var nullableType = reflect.TypeOf((*Nullable)(nil)).Elem()
+
+// asNullable returns the Nullable implemented by a pointer to val, unless
+// the input already has the type of val and can be assigned directly.
+func asNullable(input interface{}, val reflect.Value) (Nullable, bool) {
+	if !val.CanSet() || val.Kind() == reflect.Interface || val.Kind() == reflect.Ptr {
+		return nil, false
+	}
+
+	if input != nil && reflect.TypeOf(input) == val.Type() {
+		return nil, false
+	}
+
+	nullable, ok := val.Addr().Interface().(Nullable)
+	return nullable, ok
+}
+
+func (d *Decoder) decodeNullable(name string, data interface{}, nullable Nullable) error {
+	target := reflect.ValueOf(nullable.NullableValue()).Elem()
+	if data == nil {
+		target.Set(reflect.Zero(target.Type()))
+		nullable.SetPresence(Null)
+		return nil
+	}
+
+	if err := d.decode(name, data, target); err != nil {
+		return err
+	}
+
+	nullable.SetPresence(Present)
+	return nil
+}
+
+// nullableField returns the Nullable implemented by a pointer to a copy
+// of the struct field v, so it can be used even if v is not addressable.
+func nullableField(v reflect.Value) (Nullable, bool) {
+	if !v.CanInterface() || !reflect.PtrTo(v.Type()).Implements(nullableType) {
+		return nil, false
+	}
+
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	nullable, ok := ptr.Interface().(Nullable)
+	return nullable, ok
+}
@@ -0,0 +1,94 @@
+package mapstructure
+
+import (
+	"reflect"
+	"testing"
+)
+
+// OptionalString is a typed Nullable.
+type OptionalString struct {
+	State Presence
+	Value string
+}
+
+func (o *OptionalString) NullableValue() interface{} { return &o.Value }
+func (o *OptionalString) SetPresence(p Presence)     { o.State = p }
+func (o *OptionalString) Presence() Presence         { return o.State }
+
+type PatchUser struct {
+	Name     OptionalString `json:"name"`
+	Nickname OptionalString `json:"nickname"`
+	Email    OptionalString `json:"email"`
+	Age      Optional       `json:"age"`
+}
+
+func TestDecode_Nullable(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"name":     "alice",
+		"nickname": nil,
+		"age":      30,
+	}
+
+	result := PatchUser{Nickname: OptionalString{State: Present, Value: "al"}}
+	if err := Decode(input, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := PatchUser{
+		Name:     OptionalString{State: Present, Value: "alice"},
+		Nickname: OptionalString{State: Null},
+		Email:    OptionalString{State: Absent},
+		Age:      Optional{State: Present, Value: 30},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecode_NullableError(t *testing.T) {
+	t.Parallel()
+
+	var result PatchUser
+	if err := Decode(map[string]interface{}{"name": 1}, &result); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if result.Name.State != Absent {
+		t.Fatalf("bad: %s", result.Name.State)
+	}
+}
+
+func TestEncode_Nullable(t *testing.T) {
+	t.Parallel()
+
+	input := PatchUser{
+		Name:     OptionalString{State: Present, Value: "alice"},
+		Nickname: OptionalString{State: Null, Value: "ignored"},
+		Age:      Optional{State: Present, Value: 30},
+	}
+
+	var result map[string]interface{}
+	if err := Decode(input, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":     "alice",
+		"nickname": nil,
+		"age":      30,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	var roundTrip PatchUser
+	if err := Decode(result, &roundTrip); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	input.Nickname.Value = ""
+	if !reflect.DeepEqual(roundTrip, input) {
+		t.Fatalf("bad: %#v", roundTrip)
+	}
+}
@@ -0,0 +1,38 @@
+package mapstructure
+
+import (
+	"errors"
+	"reflect"
+)
+
+// MergePatch applies an RFC 7386 JSON Merge Patch, usually the decoded
+// body of a PATCH request, to the value target points to.
+//
+// Objects in the patch are merged recursively into structs and maps,
+// while slices and arrays are replaced as a whole. A nil value resets a
+// struct field to its zero value, or to Null for a Nullable field, and
+// deletes a map key. Fields are matched like in Decode and errors carry
+// the path of the offending value. target is updated in place, so it may
+// be partially patched if an error is returned.
+func MergePatch(target interface{}, patch map[string]interface{}) error {
+	val := reflect.ValueOf(target)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("target must be a non-nil pointer")
+	}
+
+	return (&Decoder{Result: target}).MergePatch(patch)
+}
+
+// MergePatch applies an RFC 7386 JSON Merge Patch to the target pointer
+// specified by the configuration. The null policy of the decoder is
+// replaced by NullZero for the duration of the call, while "null" tags
+// still apply.
+func (d *Decoder) MergePatch(patch map[string]interface{}) error {
+	policy := d.NullPolicy
+	d.NullPolicy, d.patch = NullZero, true
+	defer func() {
+		d.NullPolicy, d.patch = policy, false
+	}()
+
+	return d.Decode(patch)
+}
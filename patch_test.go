@@ -0,0 +1,121 @@
+package mapstructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type PatchServer struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type PatchDocument struct {
+	Title    string                 `json:"title"`
+	Author   *PatchServer           `json:"author"`
+	Server   PatchServer            `json:"server"`
+	Tags     []string               `json:"tags"`
+	Labels   map[string]string      `json:"labels"`
+	Servers  map[string]PatchServer `json:"servers"`
+	Extra    map[string]interface{} `json:"extra"`
+	Nickname OptionalString         `json:"nickname"`
+}
+
+func TestMergePatch(t *testing.T) {
+	t.Parallel()
+
+	target := PatchDocument{
+		Title:  "Goodbye!",
+		Author: &PatchServer{Host: "a", Port: 1},
+		Server: PatchServer{Host: "s", Port: 2},
+		Tags:   []string{"a", "b", "c"},
+		Labels: map[string]string{"keep": "1", "drop": "2"},
+		Servers: map[string]PatchServer{
+			"main": {Host: "m", Port: 3},
+		},
+		Extra: map[string]interface{}{
+			"nested": map[string]interface{}{"a": 1, "b": 2},
+			"scalar": "x",
+		},
+		Nickname: OptionalString{State: Present, Value: "nick"},
+	}
+
+	patch := map[string]interface{}{
+		"title":  "Hello!",
+		"author": nil,
+		"server": map[string]interface{}{"port": 20},
+		"tags":   []interface{}{"z"},
+		"labels": map[string]interface{}{"drop": nil, "new": "3"},
+		"servers": map[string]interface{}{
+			"main": map[string]interface{}{"port": 30},
+		},
+		"extra": map[string]interface{}{
+			"nested": map[string]interface{}{"b": nil, "c": 3},
+			"scalar": map[string]interface{}{"d": 4, "e": nil},
+		},
+		"nickname": nil,
+	}
+
+	if err := MergePatch(&target, patch); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := PatchDocument{
+		Title:  "Hello!",
+		Server: PatchServer{Host: "s", Port: 20},
+		Tags:   []string{"z"},
+		Labels: map[string]string{"keep": "1", "new": "3"},
+		Servers: map[string]PatchServer{
+			"main": {Host: "m", Port: 30},
+		},
+		Extra: map[string]interface{}{
+			"nested": map[string]interface{}{"a": 1, "c": 3},
+			"scalar": map[string]interface{}{"d": 4},
+		},
+		Nickname: OptionalString{State: Null},
+	}
+	if !reflect.DeepEqual(target, expected) {
+		t.Fatalf("bad: %#v", target)
+	}
+}
+
+func TestMergePatch_EmptyArray(t *testing.T) {
+	t.Parallel()
+
+	target := PatchDocument{Tags: []string{"a"}}
+	if err := MergePatch(&target, map[string]interface{}{"tags": []interface{}{}}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if target.Tags == nil || len(target.Tags) != 0 {
+		t.Fatalf("bad: %#v", target.Tags)
+	}
+}
+
+func TestMergePatch_Errors(t *testing.T) {
+	t.Parallel()
+
+	var target PatchDocument
+	d := &Decoder{Result: &target, NullPolicy: NullError}
+	err := d.MergePatch(map[string]interface{}{
+		"server": map[string]interface{}{"port": "http"},
+		"title":  nil,
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if !strings.Contains(err.Error(), "'server.port'") {
+		t.Fatalf("bad error: %s", err)
+	}
+
+	// The decoder is left as it was configured.
+	if d.NullPolicy != NullError || d.patch {
+		t.Fatalf("bad decoder: %#v", d)
+	}
+
+	if err := d.Decode(map[string]interface{}{"title": nil}); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,107 @@
+package mapstructure
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Get returns the value found at path in obj, which may be a struct, map,
+// slice or a pointer to one. The path uses the same format as errors,
+// such as "servers[0].tls.cert": struct fields are found by key like in
+// Decode, map keys and slice indexes are given in brackets or after a
+// dot. The value returned is a copy.
+func Get(obj interface{}, path string) (interface{}, error) {
+	return (&Decoder{Result: obj}).Get(path)
+}
+
+// Get returns the value found at path in the configured Result.
+func (d *Decoder) Get(path string) (interface{}, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	val := reflect.ValueOf(d.Result)
+	if !val.IsValid() {
+		return nil, errors.New("obj must not be nil")
+	}
+
+	found, err := d.lookupPath("", val, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	return deepCopy(found).Interface(), nil
+}
+
+// Set decodes value into the type found at path in obj, which must be a
+// pointer, replacing the previous value. Nil pointers and maps and missing
+// map keys along the path are created.
+func Set(obj interface{}, path string, value interface{}) error {
+	val := reflect.ValueOf(obj)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("obj must be a non-nil pointer")
+	}
+
+	return (&Decoder{Result: obj}).Set(path, value)
+}
+
+// Set decodes value into the type found at path in the configured Result.
+func (d *Decoder) Set(path string, value interface{}) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	d.reset()
+	return d.walkPath("", reflect.ValueOf(d.Result).Elem(), segments, true, func(name string, val reflect.Value) error {
+		result, err := d.patchDecode(name, val.Type(), value)
+		if err != nil {
+			return err
+		}
+
+		val.Set(result)
+		return nil
+	})
+}
+
+// parsePath splits a path such as "servers[0].tls.cert" into its
+// segments. The empty path refers to the value itself.
+func parsePath(path string) ([]string, error) {
+	var segments []string
+	for rest := path; rest != ""; {
+		var segment string
+		if rest[0] == '[' {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path '%s': missing ']'", path)
+			}
+			segment, rest = rest[1:end], rest[end+1:]
+			if rest != "" && rest[0] != '.' && rest[0] != '[' {
+				return nil, fmt.Errorf("invalid path '%s': unexpected '%c' after ']'", path, rest[0])
+			}
+		} else {
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segment, rest = rest[:end], rest[end:]
+			if segment == "" {
+				return nil, fmt.Errorf("invalid path '%s': empty segment", path)
+			}
+		}
+
+		if rest != "" && rest[0] == '.' {
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("invalid path '%s': empty segment", path)
+			}
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
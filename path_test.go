@@ -0,0 +1,209 @@
+package mapstructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type PathTLS struct {
+	Cert string `json:"cert"`
+}
+
+type PathServer struct {
+	Host string   `json:"host"`
+	Port int      `json:"port"`
+	TLS  *PathTLS `json:"tls"`
+}
+
+type PathConfig struct {
+	Servers []PathServer           `json:"servers"`
+	Flags   map[string]bool        `json:"flags"`
+	Limits  map[int]string         `json:"limits"`
+	Extra   map[string]interface{} `json:"extra"`
+}
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+
+	config := PathConfig{
+		Servers: []PathServer{
+			{Host: "a", TLS: &PathTLS{Cert: "a.pem"}},
+		},
+		Flags:  map[string]bool{"beta": true},
+		Limits: map[int]string{10: "ten"},
+		Extra: map[string]interface{}{
+			"list": []interface{}{"x", map[string]interface{}{"y": 1}},
+		},
+	}
+
+	cases := []struct {
+		path     string
+		expected interface{}
+	}{
+		{"servers[0].tls.cert", "a.pem"},
+		{"servers.0.host", "a"},
+		{"flags[beta]", true},
+		{"limits[10]", "ten"},
+		{"extra.list[1].y", 1},
+		{"servers[0].TLS", &PathTLS{Cert: "a.pem"}},
+	}
+
+	for _, tc := range cases {
+		for _, obj := range []interface{}{config, &config} {
+			result, err := Get(obj, tc.path)
+			if err != nil {
+				t.Fatalf("%s: err: %s", tc.path, err)
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Fatalf("%s: bad: %#v", tc.path, result)
+			}
+		}
+	}
+}
+
+func TestGet_errors(t *testing.T) {
+	t.Parallel()
+
+	config := PathConfig{
+		Servers: []PathServer{{Host: "a"}},
+	}
+
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{"servers[1]", "'servers' index 1 is out of range, length is 1"},
+		{"servers[0].tls.cert", "'servers[0].tls' is nil"},
+		{"servers[0].name", "'servers[0]' has no field 'name'"},
+		{"flags[beta]", "'flags[beta]' does not exist"},
+		{"limits[ten]", "'limits[ten]'"},
+		{"servers[0", "invalid path 'servers[0': missing ']'"},
+		{"servers.", "invalid path 'servers.': empty segment"},
+	}
+
+	for _, tc := range cases {
+		_, err := Get(&config, tc.path)
+		if err == nil {
+			t.Fatalf("%s: should error", tc.path)
+		}
+		if !strings.Contains(err.Error(), tc.expected) {
+			t.Fatalf("%s: bad: %s", tc.path, err)
+		}
+	}
+}
+
+func TestGet_copy(t *testing.T) {
+	t.Parallel()
+
+	config := PathConfig{
+		Flags: map[string]bool{"beta": true},
+	}
+
+	result, err := Get(&config, "flags")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result.(map[string]bool)["beta"] = false
+	if !config.Flags["beta"] {
+		t.Fatal("Get should return a copy")
+	}
+}
+
+func TestGet_cycle(t *testing.T) {
+	t.Parallel()
+
+	type Node struct {
+		Name string `json:"name"`
+		Next *Node  `json:"next"`
+	}
+
+	node := &Node{Name: "a"}
+	node.Next = node
+	input := map[string]interface{}{"node": node}
+
+	result, err := Get(input, "node.next.next.name")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result != "a" {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	result, err = Get(input, "node")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if n := result.(*Node); n == node || n.Next != n {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	config := PathConfig{
+		Servers: []PathServer{{Host: "a", Port: 1}},
+	}
+
+	sets := []struct {
+		path  string
+		value interface{}
+	}{
+		{"servers[0].tls.cert", "a.pem"},
+		{"servers[0].port", 8080},
+		{"flags.beta", true},
+		{"limits[10]", "ten"},
+		{"extra.nested.value", 1},
+	}
+
+	for _, s := range sets {
+		if err := Set(&config, s.path, s.value); err != nil {
+			t.Fatalf("%s: err: %s", s.path, err)
+		}
+	}
+
+	expected := PathConfig{
+		Servers: []PathServer{
+			{Host: "a", Port: 8080, TLS: &PathTLS{Cert: "a.pem"}},
+		},
+		Flags:  map[string]bool{"beta": true},
+		Limits: map[int]string{10: "ten"},
+		Extra: map[string]interface{}{
+			"nested": map[string]interface{}{"value": 1},
+		},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Fatalf("bad: %#v", config)
+	}
+}
+
+func TestSet_decode(t *testing.T) {
+	t.Parallel()
+
+	var config PathConfig
+	err := Set(&config, "servers", []interface{}{
+		map[string]interface{}{"host": "a", "port": 1},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []PathServer{{Host: "a", Port: 1}}
+	if !reflect.DeepEqual(config.Servers, expected) {
+		t.Fatalf("bad: %#v", config.Servers)
+	}
+
+	err = Set(&config, "servers[0].port", "http")
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "'servers[0].port' expected type 'int'") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	if err := Set(config, "servers", nil); err == nil {
+		t.Fatal("should error")
+	}
+}
@@ -0,0 +1,140 @@
+package mapstructure
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the value of sensitive fields when encoding a struct
+// with Decoder.Redact set.
+const Redacted = "[REDACTED]"
+
+// sensitiveOption is the tag option marking a field as sensitive, e.g.
+// `json:"password,sensitive"`.
+const sensitiveOption = "sensitive"
+
+// hasTagOption reports whether the comma separated tag value has option
+// after its name.
+func hasTagOption(tagValue, option string) bool {
+	tagParts := strings.Split(tagValue, ",")
+	for _, tag := range tagParts[1:] {
+		if tag == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeSensitive decodes a sensitive field. Warnings found on the way
+// have their message redacted and errors are replaced by one that does
+// not include the value.
+func (d *Decoder) decodeSensitive(name string, input interface{}, val reflect.Value, policy NullPolicy) error {
+	d.redacting++
+	err := d.decodeWithNullPolicy(name, input, val, policy)
+	d.redacting--
+
+	if err != nil {
+		return fmt.Errorf("'%s' could not be decoded as '%s' (sensitive value redacted)", name, val.Type())
+	}
+
+	return nil
+}
+
+// redactedValue returns the value a sensitive field is encoded as in a
+// map with the given element type.
+func redactedValue(elemType reflect.Type) reflect.Value {
+	switch elemType.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(Redacted).Convert(elemType)
+	case reflect.Interface:
+		return reflect.ValueOf(Redacted)
+	default:
+		return reflect.Zero(elemType)
+	}
+}
+
+// RedactedMap encodes the struct input into a generic map, safe for
+// logging, in which every field tagged as sensitive is replaced by
+// Redacted. Nested structs, including those in slices, maps and behind
+// pointers, are converted to maps as well.
+func RedactedMap(input interface{}) (map[string]interface{}, error) {
+	val := reflect.Indirect(reflect.ValueOf(input))
+	if val.Kind() != reflect.Struct {
+		return nil, errors.New("input must be a struct or a pointer to a struct")
+	}
+
+	v, err := redactValue(val)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.(map[string]interface{}), nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func redactValue(val reflect.Value) (interface{}, error) {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if val.IsNil() {
+			return nil, nil
+		}
+		return redactValue(val.Elem())
+	case reflect.Struct:
+		// Values such as time.Time are kept as they are.
+		if val.Type().Implements(textMarshalerType) {
+			return val.Interface(), nil
+		}
+
+		m := make(map[string]interface{})
+		d := &Decoder{Result: &m, Redact: true}
+		if err := d.Decode(val.Interface()); err != nil {
+			return nil, err
+		}
+
+		for k, v := range m {
+			redacted, err := redactValue(reflect.ValueOf(v))
+			if err != nil {
+				return nil, err
+			}
+			m[k] = redacted
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return nil, nil
+		}
+
+		s := make([]interface{}, val.Len())
+		for i := range s {
+			redacted, err := redactValue(val.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			s[i] = redacted
+		}
+		return s, nil
+	case reflect.Map:
+		if val.IsNil() {
+			return nil, nil
+		}
+
+		m := make(map[string]interface{}, val.Len())
+		for _, k := range val.MapKeys() {
+			redacted, err := redactValue(val.MapIndex(k))
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(k.Interface())] = redacted
+		}
+		return m, nil
+	case reflect.Invalid:
+		return nil, nil
+	default:
+		return val.Interface(), nil
+	}
+}
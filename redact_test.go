@@ -0,0 +1,133 @@
+package mapstructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type RedactCredentials struct {
+	User     string `json:"user"`
+	Password string `json:"password,sensitive"`
+}
+
+type RedactConfig struct {
+	Name     string              `json:"name"`
+	APIKey   string              `json:"api_key,sensitive"`
+	Port     int                 `json:"port,sensitive"`
+	Primary  RedactCredentials   `json:"primary"`
+	Replicas []RedactCredentials `json:"replicas"`
+	Backup   *RedactCredentials  `json:"backup"`
+	Started  time.Time           `json:"started"`
+}
+
+func TestDecode_SensitiveErrors(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"api_key": 12345,
+		"port":    80.5,
+		"primary": map[string]interface{}{
+			"password": []string{"hunter2"},
+		},
+	}
+
+	var result RedactConfig
+	d := &Decoder{Result: &result}
+	err := d.Decode(input)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	expected := "2 error(s) decoding:\n\n" +
+		"* 'api_key' could not be decoded as 'string' (sensitive value redacted)\n" +
+		"* 'primary.password' could not be decoded as 'string' (sensitive value redacted)"
+	if err.Error() != expected {
+		t.Fatalf("bad error: %s", err)
+	}
+
+	if result.Port != 80 {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	for _, w := range d.Warnings {
+		if strings.Contains(w.Message, "80.5") {
+			t.Fatalf("warning leaks the value: %s", w)
+		}
+	}
+	if len(d.Warnings) != 1 || d.Warnings[0].Code != WarningLossyConversion {
+		t.Fatalf("bad warnings: %#v", d.Warnings)
+	}
+}
+
+func TestDecode_Redact(t *testing.T) {
+	t.Parallel()
+
+	input := RedactConfig{
+		Name:    "db",
+		APIKey:  "secret",
+		Port:    5432,
+		Primary: RedactCredentials{User: "admin", Password: "hunter2"},
+	}
+
+	var result map[string]interface{}
+	d := &Decoder{Result: &result, Redact: true}
+	if err := d.Decode(input); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result["api_key"] != Redacted || result["port"] != Redacted {
+		t.Errorf("bad: %#v", result)
+	}
+
+	primary := result["primary"].(map[string]interface{})
+	if primary["password"] != Redacted || primary["user"] != "admin" {
+		t.Errorf("bad: %#v", primary)
+	}
+
+	// Without Redact the values are kept.
+	result = nil
+	if err := Decode(input, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if result["api_key"] != "secret" {
+		t.Errorf("bad: %#v", result)
+	}
+}
+
+func TestRedactedMap(t *testing.T) {
+	t.Parallel()
+
+	started := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	input := &RedactConfig{
+		Name:     "db",
+		APIKey:   "secret",
+		Replicas: []RedactCredentials{{User: "r1", Password: "p1"}},
+		Backup:   &RedactCredentials{User: "b", Password: "p2"},
+		Started:  started,
+	}
+
+	result, err := RedactedMap(input)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":    "db",
+		"api_key": Redacted,
+		"port":    Redacted,
+		"primary": map[string]interface{}{
+			"user":     "",
+			"password": Redacted,
+		},
+		"replicas": []interface{}{
+			map[string]interface{}{"user": "r1", "password": Redacted},
+		},
+		"backup":  map[string]interface{}{"user": "b", "password": Redacted},
+		"started": started,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
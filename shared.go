@@ -0,0 +1,37 @@
+package mapstructure
+
+import "reflect"
+
+// sharedKey identifies the output of an input pointer or map decoded into
+// a given type. The same input decoded into two different types has two
+// different outputs.
+type sharedKey struct {
+	input visitKey
+	out   reflect.Type
+}
+
+// sharedValue returns the output the input pointer or map was already
+// decoded into for the given type.
+func (d *Decoder) sharedValue(input interface{}, outType reflect.Type) (reflect.Value, bool) {
+	key, ok := inputVisitKey(reflect.ValueOf(input))
+	if !ok {
+		return reflect.Value{}, false
+	}
+
+	out, ok := d.shared[sharedKey{input: key, out: outType}]
+	return out, ok
+}
+
+// shareValue records out as the output of the input pointer or map. It is
+// called before out is filled in, so that cycles resolve to out as well.
+func (d *Decoder) shareValue(input interface{}, out reflect.Value) {
+	key, ok := inputVisitKey(reflect.ValueOf(input))
+	if !ok {
+		return
+	}
+
+	if d.shared == nil {
+		d.shared = make(map[sharedKey]reflect.Value)
+	}
+	d.shared[sharedKey{input: key, out: out.Type()}] = out
+}
@@ -0,0 +1,90 @@
+package mapstructure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecoder_PreserveAliasing(t *testing.T) {
+	t.Parallel()
+
+	type Graph struct {
+		Left   *CycleNode
+		Right  *CycleNode
+		Labels map[string]string
+		Copy   map[string]string
+	}
+
+	type GraphOut struct {
+		Left   *CycleNodeOut
+		Right  *CycleNodeOut
+		Labels map[string]string
+		Copy   map[string]interface{}
+	}
+
+	shared := &CycleNode{Name: "shared"}
+	labels := map[string]string{"env": "prod"}
+	input := Graph{Left: shared, Right: shared, Labels: labels, Copy: labels}
+
+	var result GraphOut
+	decoder := &Decoder{Result: &result, PreserveAliasing: true}
+	if err := decoder.Decode(input); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.Left != result.Right || result.Left.Name != "shared" {
+		t.Fatalf("bad: %#v", result)
+	}
+	if !reflect.DeepEqual(result.Copy, map[string]interface{}{"env": "prod"}) {
+		t.Fatalf("bad: %#v", result.Copy)
+	}
+
+	var plain GraphOut
+	if err := Decode(input, &plain); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if plain.Left == plain.Right {
+		t.Fatal("pointers should not be shared by default")
+	}
+}
+
+func TestDecoder_PreserveAliasingMaps(t *testing.T) {
+	t.Parallel()
+
+	type Config struct {
+		Primary   map[string]int
+		Secondary map[string]int
+	}
+
+	counts := map[string]interface{}{"a": 1}
+	input := map[string]interface{}{"Primary": counts, "Secondary": counts}
+
+	var result Config
+	decoder := &Decoder{Result: &result, PreserveAliasing: true}
+	if err := decoder.Decode(input); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	result.Primary["b"] = 2
+	if result.Secondary["b"] != 2 {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecoder_PreserveAliasingCycle(t *testing.T) {
+	t.Parallel()
+
+	a := &CycleNode{Name: "a"}
+	b := &CycleNode{Name: "b", Next: a}
+	a.Next = b
+
+	var result *CycleNodeOut
+	decoder := &Decoder{Result: &result, PreserveAliasing: true}
+	if err := decoder.Decode(a); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.Name != "a" || result.Next.Name != "b" || result.Next.Next != result {
+		t.Fatalf("bad: %#v", result)
+	}
+}
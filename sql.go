@@ -0,0 +1,228 @@
+package mapstructure
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Rows is the part of *sql.Rows used to scan query results.
+type Rows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+// ScanRows scans every remaining row of rows into output, which must be a
+// pointer to a slice. Columns are matched to struct fields with the same
+// tag and name matching as map keys. []byte and string values are treated
+// as text, so they can be parsed into any scalar field or a type
+// implementing encoding.TextUnmarshaler, and NULL leaves the field at its
+// zero value.
+//
+// Rows that fail to decode are still appended so the slice index always
+// corresponds to the row, and all errors are returned together. With an
+// error limit, scanning stops once the limit is reached. rows is not
+// closed.
+func ScanRows(rows Rows, output interface{}) error {
+	val := reflect.ValueOf(output)
+	if val.Kind() != reflect.Ptr || val.Elem().Kind() != reflect.Slice {
+		return errors.New("result must be a pointer to a slice")
+	}
+
+	return (&Decoder{Result: output}).ScanRows(rows)
+}
+
+// ScanRows scans every remaining row of rows into the slice pointed to by
+// the configuration.
+func (d *Decoder) ScanRows(rows Rows) error {
+	columns, err := rowColumns(rows)
+	if err != nil {
+		return err
+	}
+
+	sliceVal := reflect.ValueOf(d.Result).Elem()
+	elemType := sliceVal.Type().Elem()
+
+	errors := make([]string, 0)
+	truncated := false
+	for row := 1; rows.Next(); row++ {
+		if d.errorLimitReached(len(errors)) {
+			truncated = true
+			break
+		}
+
+		input, err := scanRow(rows, columns)
+		if err != nil {
+			return err
+		}
+
+		elem := reflect.New(elemType)
+		sub := d.rowDecoder(elem.Interface(), len(errors))
+		err = sub.Decode(input)
+		d.Warnings = sub.Warnings
+		if err != nil {
+			for _, msg := range appendErrors(nil, err) {
+				errors = append(errors, fmt.Sprintf("row %d: %s", row, msg))
+			}
+			truncated = rowTruncated(err)
+		}
+
+		sliceVal.Set(reflect.Append(sliceVal, elem.Elem()))
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return rowErrors(errors, truncated)
+}
+
+// ScanRow scans the current row of rows into output, like rows.Scan does
+// for individual columns. rows.Next must have been called first. output is
+// reset to its zero value before the row is decoded into it.
+func ScanRow(rows Rows, output interface{}) error {
+	val := reflect.ValueOf(output)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return errors.New("result must be a non-nil pointer")
+	}
+
+	return (&Decoder{Result: output}).ScanRow(rows)
+}
+
+// ScanRow scans the current row of rows into the target pointer specified
+// by the configuration.
+func (d *Decoder) ScanRow(rows Rows) error {
+	columns, err := rowColumns(rows)
+	if err != nil {
+		return err
+	}
+
+	input, err := scanRow(rows, columns)
+	if err != nil {
+		return err
+	}
+
+	val := reflect.ValueOf(d.Result).Elem()
+	val.Set(reflect.Zero(val.Type()))
+	return d.Decode(input)
+}
+
+func rowColumns(rows Rows) ([]string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(columns))
+	for _, column := range columns {
+		if _, ok := seen[column]; ok {
+			return nil, fmt.Errorf("duplicate column '%s', use an alias in the query", column)
+		}
+		seen[column] = struct{}{}
+	}
+
+	return columns, nil
+}
+
+// scanRow scans the current row into a map from column name to value.
+func scanRow(rows Rows, columns []string) (map[string]interface{}, error) {
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	input := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		switch v := values[i].(type) {
+		case []byte:
+			input[column] = textValue{key: column, values: []string{string(v)}}
+		case string:
+			input[column] = textValue{key: column, values: []string{v}}
+		default:
+			input[column] = v
+		}
+	}
+
+	return input, nil
+}
+
+// asScanner returns the sql.Scanner implemented by a pointer to val, unless
+// the input already has the type of val and can be assigned directly, or
+// is not a value a database driver could return. Other input, such as a
+// map for a JSON column type, is decoded as usual.
+func asScanner(input interface{}, val reflect.Value) (sql.Scanner, bool) {
+	if !val.CanSet() || val.Kind() == reflect.Interface || val.Kind() == reflect.Ptr {
+		return nil, false
+	}
+
+	if input != nil && (reflect.TypeOf(input) == val.Type() || !isDriverValue(input)) {
+		return nil, false
+	}
+
+	scanner, ok := val.Addr().Interface().(sql.Scanner)
+	return scanner, ok
+}
+
+// isDriverValue reports whether input can be handed to Scan: the types
+// database drivers return, text input and driver.Valuer implementations.
+func isDriverValue(input interface{}) bool {
+	switch input.(type) {
+	case textValue, []byte, time.Time, driver.Valuer:
+		return true
+	}
+
+	switch getKind(reflect.ValueOf(input)) {
+	case reflect.Bool, reflect.Int, reflect.Uint, reflect.Float32, reflect.String:
+		return true
+	default:
+		return false
+	}
+}
+
+// asValuer returns the driver.Valuer implemented by v, if any. Nil
+// pointers are never treated as valuers.
+func asValuer(v reflect.Value) (driver.Valuer, bool) {
+	if !v.CanInterface() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return nil, false
+	}
+
+	valuer, ok := v.Interface().(driver.Valuer)
+	return valuer, ok
+}
+
+// decodeScanner hands the raw input to the Scan method of the target, so
+// nil becomes NULL. Inputs implementing driver.Valuer are converted to
+// their driver value first.
+func (d *Decoder) decodeScanner(name string, data interface{}, scanner sql.Scanner) error {
+	switch v := data.(type) {
+	case textValue:
+		if len(v.values) != 1 {
+			return fmt.Errorf("%s expected a single value, got %d", v.describe(name), len(v.values))
+		}
+		data = v.values[0]
+	case driver.Valuer:
+		if dataVal := reflect.ValueOf(data); dataVal.Kind() != reflect.Ptr || !dataVal.IsNil() {
+			value, err := v.Value()
+			if err != nil {
+				return fmt.Errorf("error decoding '%s': %s", name, err)
+			}
+			data = value
+		}
+	}
+
+	if err := scanner.Scan(data); err != nil {
+		return fmt.Errorf("error scanning into '%s': %s", name, err)
+	}
+
+	return nil
+}
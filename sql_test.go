@@ -0,0 +1,308 @@
+package mapstructure
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testRows is an in-memory implementation of Rows.
+type testRows struct {
+	columns []string
+	values  [][]interface{}
+	current int
+}
+
+func (r *testRows) Columns() ([]string, error) { return r.columns, nil }
+func (r *testRows) Err() error                 { return nil }
+
+func (r *testRows) Next() bool {
+	r.current++
+	return r.current <= len(r.values)
+}
+
+func (r *testRows) Scan(dest ...interface{}) error {
+	if len(dest) != len(r.columns) {
+		return errors.New("wrong number of destinations")
+	}
+	for i, v := range r.values[r.current-1] {
+		*dest[i].(*interface{}) = v
+	}
+	return nil
+}
+
+type SQLUser struct {
+	ID        int64 `json:"id"`
+	Name      string
+	Nickname  *string    `json:"nick_name"`
+	Balance   float64    `json:"balance"`
+	Data      []byte     `json:"data"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+}
+
+func TestScanRows(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := &testRows{
+		columns: []string{"id", "NAME", "nick_name", "balance", "data", "created_at", "deleted_at"},
+		values: [][]interface{}{
+			{int64(1), []byte("alice"), []byte("al"), []byte("1.5"), []byte("raw"), created, nil},
+			{int64(2), "bob", nil, 2.5, nil, "2020-01-02T03:04:05Z", created},
+		},
+	}
+
+	var result []SQLUser
+	if err := ScanRows(rows, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []SQLUser{
+		{ID: 1, Name: "alice", Nickname: stringPtr("al"), Balance: 1.5, Data: []byte("raw"), CreatedAt: created},
+		{ID: 2, Name: "bob", Balance: 2.5, CreatedAt: created, DeletedAt: &created},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestScanRows_Errors(t *testing.T) {
+	t.Parallel()
+
+	rows := &testRows{
+		columns: []string{"id", "balance"},
+		values: [][]interface{}{
+			{int64(1), []byte("oops")},
+			{"two", 1.0},
+		},
+	}
+
+	var result []SQLUser
+	err := ScanRows(rows, &result)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	for _, s := range []string{"row 1: cannot parse 'balance'", "row 2: cannot parse 'id'"} {
+		if !strings.Contains(err.Error(), s) {
+			t.Errorf("error should contain %q: %s", s, err)
+		}
+	}
+
+	if len(result) != 2 {
+		t.Errorf("bad: %#v", result)
+	}
+}
+
+func TestScanRows_FailFast(t *testing.T) {
+	t.Parallel()
+
+	rows := &testRows{
+		columns: []string{"id", "balance"},
+		values: [][]interface{}{
+			{int64(1), 1.0},
+			{"two", 2.0},
+			{"three", 3.0},
+		},
+	}
+
+	var result []SQLUser
+	decoder := &Decoder{Result: &result, FailFast: true}
+	err := decoder.ScanRows(rows)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	derr := err.(*Error)
+	if !derr.Truncated || len(derr.Errors) != 1 || !strings.Contains(derr.Errors[0], "row 2:") {
+		t.Fatalf("bad: %#v", derr)
+	}
+	if len(result) != 2 {
+		t.Fatalf("scanning should stop at the first error: %#v", result)
+	}
+
+	// Failing at the last row is not truncated.
+	rows = &testRows{
+		columns: []string{"id"},
+		values:  [][]interface{}{{int64(1)}, {"two"}},
+	}
+
+	result = nil
+	decoder = &Decoder{Result: &result, FailFast: true}
+	err = decoder.ScanRows(rows)
+	if derr, ok := err.(*Error); !ok || derr.Truncated || len(derr.Errors) != 1 {
+		t.Fatalf("bad: %#v", err)
+	}
+}
+
+func TestScanRow(t *testing.T) {
+	t.Parallel()
+
+	rows := &testRows{
+		columns: []string{"id", "nick_name"},
+		values: [][]interface{}{
+			{int64(1), nil},
+		},
+	}
+
+	result := SQLUser{Name: "stale", Nickname: stringPtr("stale")}
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	if err := ScanRow(rows, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !reflect.DeepEqual(result, SQLUser{ID: 1}) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestScanRows_DuplicateColumn(t *testing.T) {
+	t.Parallel()
+
+	rows := &testRows{columns: []string{"id", "id"}}
+
+	var result []SQLUser
+	if err := ScanRows(rows, &result); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+type SQLNullable struct {
+	Name  sql.NullString `json:"name"`
+	Age   sql.NullInt64  `json:"age"`
+	Price Money          `json:"price"`
+}
+
+// Money stores an amount in cents and is scanned from a decimal string.
+type Money int64
+
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return err
+		}
+		*m = Money(math.Round(f * 100))
+	case float64:
+		*m = Money(math.Round(v * 100))
+	case nil:
+		*m = 0
+	default:
+		return fmt.Errorf("unsupported money type %T", src)
+	}
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return fmt.Sprintf("%d.%02d", m/100, m%100), nil
+}
+
+func TestDecode_Scanner(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"name":  "alice",
+		"age":   nil,
+		"price": "12.34",
+	}
+
+	result := SQLNullable{Age: sql.NullInt64{Int64: 3, Valid: true}}
+	if err := Decode(input, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := SQLNullable{
+		Name:  sql.NullString{String: "alice", Valid: true},
+		Price: 1234,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	err := Decode(map[string]interface{}{"price": true}, &result)
+	if err == nil || !strings.Contains(err.Error(), "error scanning into 'price'") {
+		t.Fatalf("bad error: %v", err)
+	}
+}
+
+// SQLAttrs is a JSON column type whose Scan only accepts raw bytes.
+type SQLAttrs map[string]interface{}
+
+func (a *SQLAttrs) Scan(src interface{}) error {
+	b, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported attrs type %T", src)
+	}
+	return json.Unmarshal(b, a)
+}
+
+func TestDecode_ScannerNonDriverInput(t *testing.T) {
+	t.Parallel()
+
+	type Row struct {
+		Attrs SQLAttrs `json:"attrs"`
+	}
+
+	var result Row
+	input := map[string]interface{}{
+		"attrs": map[string]interface{}{"color": "red"},
+	}
+	if err := Decode(input, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(result.Attrs, SQLAttrs{"color": "red"}) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	result = Row{}
+	input = map[string]interface{}{"attrs": []byte(`{"size":2}`)}
+	if err := Decode(input, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(result.Attrs, SQLAttrs{"size": float64(2)}) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecode_Valuer(t *testing.T) {
+	t.Parallel()
+
+	input := SQLNullable{
+		Name:  sql.NullString{String: "alice", Valid: true},
+		Price: 1234,
+	}
+
+	var result map[string]interface{}
+	if err := Decode(input, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":  "alice",
+		"age":   nil,
+		"price": "12.34",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	var roundTrip SQLNullable
+	if err := Decode(result, &roundTrip); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(roundTrip, input) {
+		t.Fatalf("bad: %#v", roundTrip)
+	}
+}
@@ -0,0 +1,265 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// structPlan maps the fields of a source struct type to those of a target
+// struct type, so that decoding one into the other doesn't have to go
+// through a map. It matches fields the same way decodeStructFromMap
+// matches map keys.
+type structPlan struct {
+	sources []planSource
+	fields  []planField
+
+	// viaMap is set if the fields can't be mapped directly, such as when
+	// two source fields have the same key.
+	viaMap bool
+}
+
+// planSource is an exported field of the source struct.
+type planSource struct {
+	field     reflect.StructField
+	key       string
+	omitempty bool
+	sensitive bool
+}
+
+// planField is a field of the target struct and the source fields that
+// each of its names can be given by.
+type planField struct {
+	field reflect.StructField
+	key   string
+	names []planName
+}
+
+type planName struct {
+	name       string
+	deprecated bool
+
+	// sources are the indexes into structPlan.sources matching the name,
+	// the exact match first and then the case-insensitive ones.
+	sources []int
+}
+
+type structPlanKey struct {
+	src, dst reflect.Type
+}
+
+// structPlans caches the plans of decoders without a NamingStrategy, as
+// they only depend on the two types.
+var structPlans sync.Map
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+func (d *Decoder) structPlan(src, dst reflect.Type) *structPlan {
+	if d.NamingStrategy != nil {
+		return d.buildStructPlan(src, dst)
+	}
+
+	key := structPlanKey{src: src, dst: dst}
+	if plan, ok := structPlans.Load(key); ok {
+		return plan.(*structPlan)
+	}
+
+	plan, _ := structPlans.LoadOrStore(key, d.buildStructPlan(src, dst))
+	return plan.(*structPlan)
+}
+
+func (d *Decoder) buildStructPlan(src, dst reflect.Type) *structPlan {
+	plan := &structPlan{}
+
+	keys := make(map[string]int)
+	for i := 0; i < src.NumField(); i++ {
+		f := src.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tagValue := f.Tag.Get(tagName)
+		tagParts := strings.Split(tagValue, ",")
+		key := d.fieldKey(f.Name)
+		if tagParts[0] != "" {
+			if tagParts[0] == "-" {
+				continue
+			}
+			key = tagParts[0]
+		}
+
+		if _, ok := keys[key]; ok {
+			return &structPlan{viaMap: true}
+		}
+		keys[key] = len(plan.sources)
+
+		source := planSource{
+			field:     f,
+			key:       key,
+			sensitive: hasTagOption(tagValue, sensitiveOption),
+		}
+		for _, tag := range tagParts[1:] {
+			if tag == "omitempty" {
+				source.omitempty = true
+			}
+		}
+		plan.sources = append(plan.sources, source)
+	}
+
+	for i := 0; i < dst.NumField(); i++ {
+		f := dst.Field(i)
+		key := d.fieldKey(f.Name)
+		if tagValue := strings.SplitN(f.Tag.Get(tagName), ",", 2)[0]; tagValue != "" {
+			key = tagValue
+		}
+
+		field := planField{field: f, key: key}
+		field.names = append(field.names, plan.planName(key, false, keys))
+		for _, alias := range splitTagList(f.Tag.Get(aliasTagName)) {
+			field.names = append(field.names, plan.planName(alias, false, keys))
+		}
+		for _, deprecated := range splitTagList(f.Tag.Get(deprecatedTagName)) {
+			field.names = append(field.names, plan.planName(deprecated, true, keys))
+		}
+		plan.fields = append(plan.fields, field)
+	}
+
+	return plan
+}
+
+func (p *structPlan) planName(name string, deprecated bool, keys map[string]int) planName {
+	n := planName{name: name, deprecated: deprecated}
+	exact, ok := keys[name]
+	if ok {
+		n.sources = append(n.sources, exact)
+	}
+
+	for i, source := range p.sources {
+		if (!ok || i != exact) && strings.EqualFold(source.key, name) {
+			n.sources = append(n.sources, i)
+		}
+	}
+
+	return n
+}
+
+// decodeStructFromStruct decodes the struct dataVal into the struct val
+// field by field. Nested structs are passed on as they are, so they are
+// only converted where the target types differ.
+func (d *Decoder) decodeStructFromStruct(name string, dataVal, val reflect.Value) error {
+	plan := d.structPlan(dataVal.Type(), val.Type())
+	if plan.viaMap {
+		return d.decodeStructViaMap(name, dataVal, val)
+	}
+
+	// Resolve the source values the same way decodeMapFromStruct does,
+	// leaving out the ones it would leave out of the map.
+	inputs := make([]interface{}, len(plan.sources))
+	present := make([]bool, len(plan.sources))
+	for i, source := range plan.sources {
+		v, ok, err := structFieldValue(source.field, dataVal.FieldByIndex(source.field.Index), interfaceType)
+		if err != nil {
+			return err
+		}
+		if !ok || (source.omitempty && isEmptyValue(v)) {
+			continue
+		}
+
+		present[i] = true
+		if d.Redact && source.sensitive {
+			inputs[i] = Redacted
+		} else {
+			inputs[i] = v.Interface()
+		}
+	}
+
+	used := make([]bool, len(plan.sources))
+	errors := make([]string, 0)
+	stopped := false
+	for _, f := range plan.fields {
+		fieldName := f.key
+		if name != "" {
+			fieldName = fmt.Sprintf("%s.%s", name, f.key)
+		}
+
+		found, foundName, err := plan.lookup(fieldName, f, present)
+		if err != nil {
+			errors = d.appendErrors(errors, err)
+			continue
+		}
+		if found < 0 {
+			continue
+		}
+
+		// Only fields with input are skipped by stopping.
+		if stopped = d.stopLoop(); stopped {
+			break
+		}
+		used[found] = true
+
+		if key := plan.sources[found].key; key != foundName.name {
+			d.warn(fieldName, WarningCaseInsensitiveKey,
+				"key '%v' matched '%s' case-insensitively", key, foundName.name)
+		}
+		if foundName.deprecated {
+			d.warn(fieldName, WarningDeprecatedKey,
+				"key '%s' is deprecated, use '%s' instead", foundName.name, f.key)
+		}
+
+		fieldValue := val.FieldByIndex(f.field.Index)
+		if !fieldValue.CanSet() {
+			continue
+		}
+
+		if err := d.decodeField(fieldName, f.field, inputs[found], fieldValue); err != nil {
+			errors = d.appendErrors(errors, err)
+		}
+	}
+
+	// Keys after decoding stopped were never looked at.
+	if !stopped {
+		var unused []string
+		for i, source := range plan.sources {
+			if present[i] && !used[i] {
+				unused = append(unused, source.key)
+			}
+		}
+		d.warnUnused(name, unused)
+	}
+
+	if len(errors) > 0 {
+		return &Error{Errors: errors}
+	}
+
+	return nil
+}
+
+// lookup finds the present source field for the target field f like
+// lookupField, returning -1 if there is none.
+func (p *structPlan) lookup(fieldName string, f planField, present []bool) (int, planName, error) {
+	found := -1
+	var foundName planName
+	for _, n := range f.names {
+		src := -1
+		for _, i := range n.sources {
+			if present[i] {
+				src = i
+				break
+			}
+		}
+		if src < 0 || src == found {
+			continue
+		}
+
+		if found >= 0 {
+			return -1, planName{}, fmt.Errorf(
+				"'%s' is given more than once, as '%v' and '%v'",
+				fieldName, p.sources[found].key, p.sources[src].key)
+		}
+
+		found, foundName = src, n
+	}
+
+	return found, foundName, nil
+}
@@ -0,0 +1,201 @@
+package mapstructure
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type StructAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type StructAddressOut struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type StructBase struct {
+	ID int `json:"id"`
+}
+
+type StructSource struct {
+	StructBase
+	Name     string         `json:"name"`
+	Age      int            `json:"age"`
+	Home     StructAddress  `json:"home"`
+	Work     StructAddress  `json:"work"`
+	Other    StructAddress  `json:"other"`
+	Nickname string         `json:"nickname,omitempty"`
+	Optional OptionalString `json:"optional"`
+	secret   string
+}
+
+type StructTarget struct {
+	StructBase
+	Name     string           `json:"name"`
+	Age      int64            `json:"age"`
+	Home     StructAddress    `json:"home"`
+	Work     StructAddressOut `json:"work"`
+	Other    interface{}      `json:"other"`
+	Nickname string           `json:"nickname"`
+	Optional *string          `json:"optional"`
+}
+
+func TestDecode_structToStruct(t *testing.T) {
+	t.Parallel()
+
+	input := StructSource{
+		StructBase: StructBase{ID: 7},
+		Name:       "name",
+		Age:        30,
+		Home:       StructAddress{Street: "a", City: "b"},
+		Work:       StructAddress{Street: "c", City: "d"},
+		Other:      StructAddress{Street: "e", City: "f"},
+		Optional:   OptionalString{State: Null},
+		secret:     "x",
+	}
+
+	result := StructTarget{Nickname: "kept"}
+	if err := Decode(input, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := StructTarget{
+		StructBase: StructBase{ID: 7},
+		Name:       "name",
+		Age:        30,
+		Home:       StructAddress{Street: "a", City: "b"},
+		Work:       StructAddressOut{Street: "c", City: "d"},
+		Other:      StructAddress{Street: "e", City: "f"},
+		Nickname:   "kept",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	var viaMap StructTarget
+	viaMap.Nickname = "kept"
+	decoder := &Decoder{Result: &viaMap}
+	err := decoder.decodeStructViaMap("", reflect.ValueOf(input), reflect.ValueOf(&viaMap).Elem())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The intermediate map flattens nested structs.
+	expected.Other = map[string]interface{}{"street": "e", "city": "f"}
+	if !reflect.DeepEqual(viaMap, expected) {
+		t.Fatalf("bad: %#v", viaMap)
+	}
+}
+
+func TestDecode_structToStructWarnings(t *testing.T) {
+	t.Parallel()
+
+	type Source struct {
+		NAME    string
+		OldPort int
+		Extra   bool
+	}
+
+	type Target struct {
+		Name string
+		Port int `deprecated:"OldPort"`
+	}
+
+	var result Target
+	decoder := &Decoder{Result: &result}
+	if err := decoder.Decode(Source{NAME: "n", OldPort: 80, Extra: true}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result != (Target{Name: "n", Port: 80}) {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	expected := []Warning{
+		{Path: "Name", Code: WarningCaseInsensitiveKey, Message: "key 'NAME' matched 'Name' case-insensitively"},
+		{Path: "Port", Code: WarningDeprecatedKey, Message: "key 'OldPort' is deprecated, use 'Port' instead"},
+		{Path: "Extra", Code: WarningUnusedKey, Message: "key does not match any field"},
+	}
+	if !reflect.DeepEqual(decoder.Warnings, expected) {
+		t.Fatalf("bad: %#v", decoder.Warnings)
+	}
+}
+
+func TestDecode_structToStructErrors(t *testing.T) {
+	t.Parallel()
+
+	type Source struct {
+		Port    string
+		OldPort int `json:",omitempty"`
+	}
+
+	type Target struct {
+		Port int `alias:"OldPort"`
+	}
+
+	var result Target
+	err := Decode(Source{Port: "x", OldPort: 1}, &result)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "'Port' is given more than once, as 'Port' and 'OldPort'") {
+		t.Fatalf("bad: %s", err)
+	}
+
+	err = Decode(Source{Port: "x"}, &result)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "'Port' expected type 'int', got unconvertible type 'string'") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestDecode_structToStructNaming(t *testing.T) {
+	t.Parallel()
+
+	type Source struct {
+		UserName string
+		Tagged   string `json:"user_id"`
+	}
+
+	type Target struct {
+		UserName string
+		UserID   string
+	}
+
+	var result Target
+	decoder := &Decoder{Result: &result, NamingStrategy: SnakeCase}
+	if err := decoder.Decode(Source{UserName: "n", Tagged: "1"}); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result != (Target{UserName: "n", UserID: "1"}) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestDecode_structToStructDuplicateKeys(t *testing.T) {
+	t.Parallel()
+
+	type Source struct {
+		Name  string
+		Other string `json:"Name"`
+	}
+
+	type Target struct {
+		Name string
+	}
+
+	var result Target
+	if err := Decode(Source{Name: "a", Other: "b"}, &result); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.Name != "b" {
+		t.Fatalf("bad: %#v", result)
+	}
+}
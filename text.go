@@ -0,0 +1,126 @@
+package mapstructure
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// textValue is a leaf of the input built from a text based source such
+// as form data, headers or CSV. It keeps the original key, and optionally
+// its position, so errors refer to what the client actually sent.
+type textValue struct {
+	key    string
+	pos    string
+	values []string
+}
+
+// describe names the source of the value in error messages, including the
+// decoded field when it differs from the original key.
+func (tv textValue) describe(name string) string {
+	s := fmt.Sprintf("'%s'", tv.key)
+	if tv.pos != "" {
+		s = fmt.Sprintf("%s %s", tv.pos, s)
+	}
+	if name != "" && name != tv.key {
+		s = fmt.Sprintf("%s (field '%s')", s, name)
+	}
+
+	return s
+}
+
+// decodeTextValue unwraps a single value for scalar targets and parses
+// it into the target kind, while slice targets receive every value.
+// Targets implementing encoding.TextUnmarshaler and []byte targets are
+// given the single value as is.
+func (d *Decoder) decodeTextValue(name string, tv textValue, val reflect.Value) error {
+	if val.Kind() == reflect.Ptr {
+		return d.decodePtr(name, tv, val)
+	}
+
+	if val.CanAddr() {
+		if u, ok := val.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if len(tv.values) != 1 {
+				return fmt.Errorf("%s expected a single value, got %d", tv.describe(name), len(tv.values))
+			}
+			if err := u.UnmarshalText([]byte(tv.values[0])); err != nil {
+				return fmt.Errorf("cannot parse %s as %s: %s", tv.describe(name), val.Type(), err)
+			}
+			return nil
+		}
+	}
+
+	if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8 && len(tv.values) == 1 {
+		val.SetBytes([]byte(tv.values[0]))
+		return nil
+	}
+
+	switch getKind(val) {
+	case reflect.Interface:
+		if len(tv.values) == 1 {
+			return d.decodeBasic(name, tv.values[0], val)
+		}
+		return d.decodeBasic(name, tv.values, val)
+	case reflect.Slice, reflect.Array:
+		elems := make([]interface{}, len(tv.values))
+		for i, v := range tv.values {
+			elems[i] = textValue{key: tv.key, pos: tv.pos, values: []string{v}}
+		}
+		return d.decode(name, elems, val)
+	}
+
+	if len(tv.values) != 1 {
+		return fmt.Errorf("%s expected a single value, got %d", tv.describe(name), len(tv.values))
+	}
+
+	s := tv.values[0]
+	switch getKind(val) {
+	case reflect.String:
+		val.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("cannot parse %s as bool: %s", tv.describe(name), err)
+		}
+		val.SetBool(b)
+	case reflect.Int:
+		if val.Type() == durationType {
+			dur, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("cannot parse %s as duration: %s", tv.describe(name), err)
+			}
+			val.SetInt(int64(dur))
+			return nil
+		}
+
+		i, err := strconv.ParseInt(s, 0, val.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %s as int: %s", tv.describe(name), err)
+		}
+		val.SetInt(i)
+	case reflect.Uint:
+		i, err := strconv.ParseUint(s, 0, val.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %s as uint: %s", tv.describe(name), err)
+		}
+		val.SetUint(i)
+	case reflect.Float32:
+		f, err := strconv.ParseFloat(s, val.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("cannot parse %s as float: %s", tv.describe(name), err)
+		}
+		val.SetFloat(f)
+	case reflect.Complex64:
+		c, err := parseComplex(s)
+		if err != nil {
+			return fmt.Errorf("cannot parse %s as complex: %s", tv.describe(name), err)
+		}
+		val.SetComplex(c)
+	default:
+		return fmt.Errorf("%s expected type '%s', got a text value", tv.describe(name), val.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// walkPath follows the segments of path from val, which must be settable,
+// and calls fn with the settable value found at the end. Struct fields are
+// found by key like in decodeStructFromMap, map keys are decoded into the
+// key type and slice and array elements are found by index.
+//
+// Map values and values inside interfaces can't be set in place, so they
+// are copied, walked and stored back. If alloc is set, nil pointers and
+// maps and missing map keys on the way are created instead of being
+// reported as missing.
+func (d *Decoder) walkPath(name string, val reflect.Value, path []string, alloc bool, fn func(name string, val reflect.Value) error) error {
+	if len(path) == 0 {
+		return fn(name, val)
+	}
+
+	segment := path[0]
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			if !alloc {
+				return fmt.Errorf("'%s' is nil", name)
+			}
+			val.Set(reflect.New(val.Type().Elem()))
+		}
+		return d.walkPath(name, val.Elem(), path, alloc, fn)
+	case reflect.Interface:
+		if val.IsNil() {
+			if !alloc || val.NumMethod() != 0 {
+				return fmt.Errorf("'%s' is nil", name)
+			}
+			val.Set(reflect.ValueOf(map[string]interface{}{}))
+		}
+
+		elem := reflect.New(val.Elem().Type()).Elem()
+		elem.Set(val.Elem())
+		if err := d.walkPath(name, elem, path, alloc, fn); err != nil {
+			return err
+		}
+		val.Set(elem)
+		return nil
+	case reflect.Struct:
+		field, fieldName, ok := d.lookupStructField(val, segment)
+		if !ok {
+			return fmt.Errorf("'%s' has no field '%s'", name, segment)
+		}
+		return d.walkPath(joinPath(name, fieldName), field, path[1:], alloc, fn)
+	case reflect.Map:
+		key, err := d.pathMapKey(name, val.Type(), segment)
+		if err != nil {
+			return err
+		}
+
+		elemName := fmt.Sprintf("%s[%s]", name, segment)
+		elem := reflect.New(val.Type().Elem()).Elem()
+		if existing := val.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		} else if !alloc {
+			return fmt.Errorf("'%s' does not exist", elemName)
+		}
+
+		if err := d.walkPath(elemName, elem, path[1:], alloc, fn); err != nil {
+			return err
+		}
+
+		if val.IsNil() {
+			val.Set(reflect.MakeMap(val.Type()))
+		}
+		val.SetMapIndex(key, elem)
+		return nil
+	case reflect.Slice, reflect.Array:
+		i, err := pathIndex(name, segment, val.Len())
+		if err != nil {
+			return err
+		}
+		return d.walkPath(fmt.Sprintf("%s[%d]", name, i), val.Index(i), path[1:], alloc, fn)
+	default:
+		return fmt.Errorf("'%s' of type '%s' has no element '%s'", name, val.Type(), segment)
+	}
+}
+
+// lookupPath follows the segments of path from val like walkPath, but
+// only reads: val need not be settable and nothing is created or stored
+// back.
+func (d *Decoder) lookupPath(name string, val reflect.Value, path []string) (reflect.Value, error) {
+	for _, segment := range path {
+		for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+			if val.IsNil() {
+				return reflect.Value{}, fmt.Errorf("'%s' is nil", name)
+			}
+			val = val.Elem()
+		}
+
+		switch val.Kind() {
+		case reflect.Struct:
+			field, fieldName, ok := d.lookupStructField(val, segment)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("'%s' has no field '%s'", name, segment)
+			}
+			name, val = joinPath(name, fieldName), field
+		case reflect.Map:
+			key, err := d.pathMapKey(name, val.Type(), segment)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			name = fmt.Sprintf("%s[%s]", name, segment)
+			if val = val.MapIndex(key); !val.IsValid() {
+				return reflect.Value{}, fmt.Errorf("'%s' does not exist", name)
+			}
+		case reflect.Slice, reflect.Array:
+			i, err := pathIndex(name, segment, val.Len())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			name, val = fmt.Sprintf("%s[%d]", name, i), val.Index(i)
+		default:
+			return reflect.Value{}, fmt.Errorf("'%s' of type '%s' has no element '%s'", name, val.Type(), segment)
+		}
+	}
+
+	return val, nil
+}
+
+// lookupStructField returns the field of the struct val with the given
+// key, matching exactly first and then case-insensitively.
+func (d *Decoder) lookupStructField(val reflect.Value, key string) (reflect.Value, string, bool) {
+	typ := val.Type()
+	match := -1
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		fieldName := d.fieldKey(f.Name)
+		if tagValue := strings.SplitN(f.Tag.Get(tagName), ",", 2)[0]; tagValue != "" {
+			if tagValue == "-" {
+				continue
+			}
+			fieldName = tagValue
+		}
+
+		if fieldName == key {
+			return val.Field(i), fieldName, true
+		}
+		if match < 0 && strings.EqualFold(fieldName, key) {
+			match = i
+		}
+	}
+
+	if match < 0 {
+		return reflect.Value{}, "", false
+	}
+
+	return val.Field(match), key, true
+}
+
+// pathMapKey decodes a path segment into a key of the map type, parsing
+// it for non-string keys.
+func (d *Decoder) pathMapKey(name string, mapType reflect.Type, segment string) (reflect.Value, error) {
+	key := reflect.New(mapType.Key()).Elem()
+	keyName := fmt.Sprintf("%s[%s]", name, segment)
+	if err := d.decode(keyName, textValue{key: keyName, values: []string{segment}}, key); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return key, nil
+}
+
+// pathIndex parses a path segment as an index into a slice or array of
+// the given length.
+func pathIndex(name, segment string, length int) (int, error) {
+	i, err := strconv.Atoi(segment)
+	if err != nil || i < 0 || strconv.Itoa(i) != segment {
+		return 0, fmt.Errorf("'%s' expected an index, got '%s'", name, segment)
+	}
+
+	if i >= length {
+		return 0, fmt.Errorf("'%s' index %d is out of range, length is %d", name, i, length)
+	}
+
+	return i, nil
+}
+
+func joinPath(name, key string) string {
+	if name == "" {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", name, key)
+}
@@ -0,0 +1,87 @@
+package mapstructure
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// WarningCode identifies the kind of issue a Warning is about.
+type WarningCode string
+
+const (
+	// WarningDeprecatedKey is used when a field is set through one of
+	// the names in its "deprecated" tag.
+	WarningDeprecatedKey WarningCode = "deprecated_key"
+
+	// WarningLossyConversion is used when a float is decoded into an
+	// integer it cannot be represented by exactly.
+	WarningLossyConversion WarningCode = "lossy_conversion"
+
+	// WarningCaseInsensitiveKey is used when a key only matches a field
+	// when ignoring case.
+	WarningCaseInsensitiveKey WarningCode = "case_insensitive_key"
+
+	// WarningUnusedKey is used when a key does not match any field of the
+	// struct it is decoded into.
+	WarningUnusedKey WarningCode = "unused_key"
+)
+
+// Warning describes an issue found while decoding that did not make it
+// fail.
+type Warning struct {
+	// Path is the path of the value the warning is about, in the same
+	// format as used in errors, e.g. "servers[0].port".
+	Path string
+
+	// Code identifies the kind of issue.
+	Code WarningCode
+
+	// Message describes the issue.
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("'%s': %s", w.Path, w.Message)
+}
+
+func (d *Decoder) warn(path string, code WarningCode, format string, args ...interface{}) {
+	w := Warning{
+		Path:    path,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+	if d.redacting > 0 {
+		w.Message = "sensitive value redacted"
+	}
+
+	d.Warnings = append(d.Warnings, w)
+	if d.OnWarning != nil {
+		d.OnWarning(w)
+	}
+}
+
+// warnUnusedKeys warns about every key of dataVal that was not used to
+// decode a struct field, in sorted order.
+func (d *Decoder) warnUnusedKeys(name string, dataVal reflect.Value, usedKeys map[interface{}]struct{}) {
+	var unused []string
+	for _, dataValKey := range dataVal.MapKeys() {
+		if _, ok := usedKeys[dataValKey.Interface()]; !ok {
+			unused = append(unused, fmt.Sprint(dataValKey.Interface()))
+		}
+	}
+	d.warnUnused(name, unused)
+}
+
+// warnUnused warns about every one of the unused keys, in sorted order.
+func (d *Decoder) warnUnused(name string, unused []string) {
+	sort.Strings(unused)
+
+	for _, key := range unused {
+		path := key
+		if name != "" {
+			path = fmt.Sprintf("%s.%s", name, key)
+		}
+		d.warn(path, WarningUnusedKey, "key does not match any field")
+	}
+}
@@ -0,0 +1,65 @@
+package mapstructure
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecode_Warnings(t *testing.T) {
+	t.Parallel()
+
+	input := map[string]interface{}{
+		"VInt":    42.5,
+		"VUint":   float64(7),
+		"vstring": "foo",
+		"unknown": true,
+		"VBar": map[string]interface{}{
+			"VInt":  1,
+			"other": 2,
+		},
+	}
+
+	var result struct {
+		VInt    int
+		VUint   uint
+		VString string
+		VBar    Basic
+	}
+	var delivered []Warning
+	d := &Decoder{
+		Result: &result,
+		OnWarning: func(w Warning) {
+			delivered = append(delivered, w)
+		},
+	}
+	if err := d.Decode(input); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if result.VInt != 42 || result.VUint != 7 {
+		t.Errorf("bad: %#v", result)
+	}
+
+	expected := []Warning{
+		{Path: "VInt", Code: WarningLossyConversion, Message: "42.5 converted to 42"},
+		{Path: "VString", Code: WarningCaseInsensitiveKey, Message: "key 'vstring' matched 'VString' case-insensitively"},
+		{Path: "VBar.other", Code: WarningUnusedKey, Message: "key does not match any field"},
+		{Path: "unknown", Code: WarningUnusedKey, Message: "key does not match any field"},
+	}
+	if !reflect.DeepEqual(d.Warnings, expected) {
+		t.Errorf("bad warnings: %#v", d.Warnings)
+	}
+
+	if !reflect.DeepEqual(delivered, expected) {
+		t.Errorf("bad delivered warnings: %#v", delivered)
+	}
+}
+
+func TestWarning_String(t *testing.T) {
+	t.Parallel()
+
+	w := Warning{Path: "a.b", Code: WarningUnusedKey, Message: "key does not match any field"}
+	if w.String() != "'a.b': key does not match any field" {
+		t.Fatalf("bad: %s", w)
+	}
+}